@@ -0,0 +1,296 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// asyncBufferSize is the size of the buffer entries are written to before being flushed to the output.
+const asyncBufferSize = 256 * 1024
+
+// OverflowPolicy reports whether an entry at level is dropped when the async queue
+// is full, rather than waiting for the queue to have room, see WithAsyncOverflow.
+type OverflowPolicy func(level zapcore.Level) bool
+
+// BlockOnOverflow is an OverflowPolicy waiting for the queue to have room. It's the default policy.
+func BlockOnOverflow(zapcore.Level) bool {
+	return false
+}
+
+// DropOnOverflow is an OverflowPolicy dropping new entries.
+func DropOnOverflow(zapcore.Level) bool {
+	return true
+}
+
+// DropBelowLevel returns an OverflowPolicy dropping new entries below level,
+// and waiting for the queue to have room for the others.
+func DropBelowLevel(level zapcore.Level) OverflowPolicy {
+	return func(l zapcore.Level) bool {
+		return l < level
+	}
+}
+
+// asyncConfig configures the async writes.
+type asyncConfig struct {
+	queueSize     int
+	flushInterval time.Duration
+}
+
+// WithAsync encodes entries synchronously, and writes them to the output on a
+// background goroutine. Up to queueSize entries are queued, and written entries
+// are flushed at least every flushInterval.
+// Sync and Close flush the queued entries, and entries above the error level,
+// such as panics, are flushed before returning.
+func WithAsync(queueSize int, flushInterval time.Duration) Option {
+	return func(l *DefaultLogger) {
+		if queueSize <= 0 || flushInterval <= 0 {
+			l.config.errs = append(l.config.errs,
+				fmt.Errorf("invalid async queue size %d or flush interval %s", queueSize, flushInterval))
+			return
+		}
+
+		l.config.async = &asyncConfig{
+			queueSize:     queueSize,
+			flushInterval: flushInterval,
+		}
+	}
+}
+
+// WithAsyncOverflow sets the behavior of WithAsync when its queue is full, BlockOnOverflow by default.
+// It has no effect without WithAsync.
+func WithAsyncOverflow(policy OverflowPolicy) Option {
+	return func(l *DefaultLogger) {
+		l.config.asyncOverflow = policy
+	}
+}
+
+// AsyncDropped returns the number of entries dropped because the async queue was full.
+func (l *DefaultLogger) AsyncDropped() uint64 {
+	if l.async == nil {
+		return 0
+	}
+	return l.async.dropped.Load()
+}
+
+// asyncWriter writes encoded entries to the output on a background goroutine.
+type asyncWriter struct {
+	mu     sync.Mutex
+	out    zapcore.WriteSyncer
+	errOut zapcore.WriteSyncer
+	buf    *bufio.Writer
+
+	overflow OverflowPolicy
+	dropped  atomic.Uint64
+	queue    chan *buffer.Buffer
+	flush    chan chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	wg       sync.WaitGroup
+
+	// closeMu is read locked by Write until the entry is queued, so that Close
+	// doesn't drain the queue before. It guards closed.
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+func newAsyncWriter(out, errOut zapcore.WriteSyncer, cfg *asyncConfig, overflow OverflowPolicy) *asyncWriter {
+	if overflow == nil {
+		overflow = BlockOnOverflow
+	}
+
+	w := &asyncWriter{
+		out:      out,
+		errOut:   errOut,
+		buf:      bufio.NewWriterSize(out, asyncBufferSize),
+		overflow: overflow,
+		queue:    make(chan *buffer.Buffer, cfg.queueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.run(cfg.flushInterval)
+
+	return w
+}
+
+// Write queues the encoded entry, according to the overflow policy when the queue is full.
+// Entries are written synchronously once the writer is closed.
+func (w *asyncWriter) Write(level zapcore.Level, entry *buffer.Buffer) {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+
+	if w.closed {
+		w.writeSync(entry)
+		return
+	}
+
+	select {
+	case w.queue <- entry:
+		return
+	default:
+	}
+
+	if w.overflow(level) {
+		w.dropped.Add(1)
+		entry.Free()
+		return
+	}
+
+	// The background goroutine writes the queued entries until Close, which waits for the read lock.
+	w.queue <- entry
+}
+
+// Sync writes the queued entries, and syncs the output.
+func (w *asyncWriter) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case w.flush <- ack:
+		<-ack
+	case <-w.done:
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.out.Sync()
+}
+
+// Close writes the queued entries and stops the background goroutine.
+func (w *asyncWriter) Close() error {
+	w.once.Do(func() {
+		w.closeMu.Lock()
+		w.closed = true
+		close(w.done)
+		w.closeMu.Unlock()
+
+		w.wg.Wait()
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.out.Sync()
+}
+
+func (w *asyncWriter) run(flushInterval time.Duration) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case entry := <-w.queue:
+			w.write(entry, false)
+		case <-ticker.C:
+			w.drain()
+		case ack := <-w.flush:
+			w.drain()
+			close(ack)
+		case <-w.done:
+			w.drain()
+			return
+		}
+	}
+}
+
+// drain writes the queued entries, and flushes the buffer.
+func (w *asyncWriter) drain() {
+	for {
+		select {
+		case entry := <-w.queue:
+			w.write(entry, false)
+		default:
+			w.mu.Lock()
+			w.report(w.buf.Flush())
+			w.mu.Unlock()
+			return
+		}
+	}
+}
+
+// writeSync writes the entry and flushes the buffer.
+func (w *asyncWriter) writeSync(entry *buffer.Buffer) {
+	w.write(entry, true)
+}
+
+func (w *asyncWriter) write(entry *buffer.Buffer, flush bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.buf.Write(entry.Bytes())
+	entry.Free()
+	w.report(err)
+
+	if flush {
+		w.report(w.buf.Flush())
+	}
+}
+
+// report writes errors to the error output, as zap does.
+func (w *asyncWriter) report(err error) {
+	if err == nil {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w.errOut, "%v write error: %v\n", time.Now(), err)
+	_ = w.errOut.Sync()
+}
+
+// asyncCore is a zapcore.Core encoding entries, and writing them with an asyncWriter.
+type asyncCore struct {
+	zapcore.LevelEnabler
+
+	enc    zapcore.Encoder
+	writer *asyncWriter
+}
+
+// With adds structured context to the core.
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+
+	return &asyncCore{
+		LevelEnabler: c.LevelEnabler,
+		enc:          enc,
+		writer:       c.writer,
+	}
+}
+
+// Check adds the core to the entry when its level is enabled.
+func (c *asyncCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write encodes the entry and queues it. Entries above the error level are flushed before returning.
+func (c *asyncCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+
+	if entry.Level > zapcore.ErrorLevel {
+		// Queued entries are written first, to keep the entries order.
+		_ = c.writer.Sync()
+		c.writer.writeSync(buf)
+		return c.writer.Sync()
+	}
+
+	c.writer.Write(entry.Level, buf)
+	return nil
+}
+
+// Sync writes the queued entries, and syncs the output.
+func (c *asyncCore) Sync() error {
+	return c.writer.Sync()
+}
@@ -0,0 +1,123 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+// blockingWriter blocks writes until released.
+type blockingWriter struct {
+	once     sync.Once
+	entered  chan struct{}
+	released chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.released
+	return len(p), nil
+}
+
+func Test_WithAsync(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger, err := log.Build(log.WithAsync(100, time.Hour), log.WithOutput(&buf))
+	is.NoError(err)
+
+	for range 3 {
+		logger.Info(message)
+	}
+	is.NoError(logger.Sync())
+	is.Equal(3, strings.Count(buf.String(), message))
+
+	is.Panics(func() { logger.Panic("panic") })
+	is.Contains(buf.String(), `"message":"panic"`)
+
+	is.NoError(logger.Close())
+	logger.Info("closed")
+	is.Contains(buf.String(), `"message":"closed"`)
+
+	_, err = log.Build(log.WithAsync(0, time.Second))
+	is.Error(err)
+}
+
+func Test_WithAsyncOverflow(t *testing.T) {
+	t.Parallel()
+
+	overflow := log.WithAsyncOverflow(log.DropBelowLevel(zapcore.ErrorLevel))
+	orders := map[string][]log.Option{
+		"after WithAsync":  {log.WithAsync(1, time.Hour), overflow},
+		"before WithAsync": {overflow, log.WithAsync(1, time.Hour)},
+	}
+
+	for name, opts := range orders {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+			w := &blockingWriter{entered: make(chan struct{}), released: make(chan struct{})}
+
+			logger, err := log.Build(append(opts, log.WithOutput(w))...)
+			is.NoError(err)
+
+			// Block the background goroutine while flushing.
+			logger.Info(message)
+			go func() { _ = logger.Sync() }()
+			<-w.entered
+
+			logger.Info(message)
+			logger.Info(message)
+			logger.Warn(message)
+			is.Equal(uint64(2), logger.AsyncDropped())
+
+			close(w.released)
+			is.NoError(logger.Close())
+		})
+	}
+}
+
+func Test_WithAsync_WriteDuringClose(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger, err := log.Build(log.WithAsync(10, time.Hour), log.WithOutput(&buf))
+	is.NoError(err)
+
+	const writers, entries = 4, 100
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range entries {
+				logger.Info(message)
+			}
+		}()
+	}
+	is.NoError(logger.Close())
+	wg.Wait()
+	is.NoError(logger.Sync())
+
+	// Entries written during Close are queued before the final drain, or written synchronously.
+	is.Equal(writers*entries, strings.Count(buf.String(), message))
+}
+
+func Test_OverflowPolicy(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.False(log.BlockOnOverflow(zapcore.DebugLevel))
+	is.True(log.DropOnOverflow(zapcore.ErrorLevel))
+	is.True(log.DropBelowLevel(zapcore.WarnLevel)(zapcore.InfoLevel))
+	is.False(log.DropBelowLevel(zapcore.WarnLevel)(zapcore.WarnLevel))
+}
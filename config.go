@@ -0,0 +1,271 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+// Config is a serializable logger configuration, see NewFromConfig.
+// Zero values keep the logger defaults.
+type Config struct {
+	// Level is the default level, see ParseLevel.
+	Level string `json:"level" yaml:"level"`
+	// Levels sets levels per logger name, see ParseLevelSpec.
+	Levels string `json:"levels" yaml:"levels"`
+	// Format is the encoding of entries, see ParseFormat.
+	Format string `json:"format" yaml:"format"`
+	// Outputs are the output paths, see WithOutputPaths.
+	Outputs []string `json:"outputs" yaml:"outputs"`
+	// Sampling enables sampling when set, see WithSampling.
+	Sampling *SamplingConfig `json:"sampling" yaml:"sampling"`
+	// Sentry enables Sentry when its DSN is set.
+	Sentry SentryConfig `json:"sentry" yaml:"sentry"`
+	// Service adds the ECS service field when its name is set.
+	Service ServiceConfig `json:"service" yaml:"service"`
+	// Fields are added to every entry.
+	Fields map[string]interface{} `json:"fields" yaml:"fields"`
+}
+
+// SentryConfig configures the Sentry client.
+type SentryConfig struct {
+	DSN         string `json:"dsn"         yaml:"dsn"`
+	Environment string `json:"environment" yaml:"environment"`
+	// Release defaults to the service name@version.
+	Release string `json:"release" yaml:"release"`
+}
+
+// ServiceConfig identifies the service, see fields.Service.
+type ServiceConfig struct {
+	Name    string `json:"name"    yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// Duration is a time.Duration read from and written as a string, e.g. "1s", see time.ParseDuration.
+// JSON numbers are read as nanoseconds.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading strings and numbers of nanoseconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		text, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(text))
+	}
+
+	nanoseconds, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid duration %s: %w", data, err)
+	}
+	*d = Duration(nanoseconds)
+	return nil
+}
+
+// NewFromConfig returns a new logger configured by cfg, followed by opts.
+// Unlike WithLevel, an unknown level is an error.
+func NewFromConfig(cfg Config, opts ...Option) (*DefaultLogger, error) {
+	cfgOpts := make([]Option, 0, len(opts)+6)
+
+	if cfg.Level != "" {
+		cfgOpts = append(cfgOpts, WithStrictLevel(cfg.Level))
+	}
+	if cfg.Levels != "" {
+		cfgOpts = append(cfgOpts, WithLevelSpec(cfg.Levels))
+	}
+	if cfg.Format != "" {
+		cfgOpts = append(cfgOpts, WithFormat(cfg.Format))
+	}
+	if len(cfg.Outputs) > 0 {
+		cfgOpts = append(cfgOpts, WithOutputPaths(cfg.Outputs...))
+	}
+	if cfg.Sampling != nil {
+		cfgOpts = append(cfgOpts, WithSampling(cfg.Sampling.Initial, cfg.Sampling.Thereafter, time.Duration(cfg.Sampling.Tick)))
+	}
+	if cfg.Sentry.DSN != "" {
+		client, err := sentry.NewClient(sentry.ClientOptions{
+			Dsn:              cfg.Sentry.DSN,
+			Environment:      cfg.Sentry.Environment,
+			Release:          cfg.release(),
+			AttachStacktrace: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("sentry client: %w", err)
+		}
+		cfgOpts = append(cfgOpts, WithSentry(client))
+	}
+
+	l, err := Build(append(cfgOpts, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.With(cfg.fields()...), nil
+}
+
+// ConfigFromEnv returns the configuration read from the environment variables
+// named after prefix, e.g. with the LOG prefix:
+//
+//	LOG_LEVEL=info
+//	LOG_LEVELS=db=debug,http=warn
+//	LOG_FORMAT=ecs-json
+//	LOG_OUTPUTS=stdout,/var/log/app.log
+//	LOG_SAMPLING_INITIAL=100
+//	LOG_SAMPLING_THEREAFTER=100
+//	LOG_SAMPLING_TICK=1s
+//	LOG_SENTRY_DSN=https://key@sentry.example.com/1
+//	LOG_SENTRY_ENVIRONMENT=production
+//	LOG_SENTRY_RELEASE=myapp@v1.0.0
+//	LOG_SERVICE_NAME=myapp
+//	LOG_SERVICE_VERSION=v1.0.0
+//	LOG_FIELDS=team=core,region=eu
+//
+// Sampling is enabled when LOG_SAMPLING_TICK is set.
+// An invalid level, level spec, format, sampling setting or fields list is an error.
+func ConfigFromEnv(prefix string) (Config, error) {
+	env := func(name string) string {
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		return strings.TrimSpace(os.Getenv(name))
+	}
+
+	cfg := Config{
+		Level:  env("LEVEL"),
+		Levels: env("LEVELS"),
+		Format: env("FORMAT"),
+		Sentry: SentryConfig{
+			DSN:         env("SENTRY_DSN"),
+			Environment: env("SENTRY_ENVIRONMENT"),
+			Release:     env("SENTRY_RELEASE"),
+		},
+		Service: ServiceConfig{
+			Name:    env("SERVICE_NAME"),
+			Version: env("SERVICE_VERSION"),
+		},
+	}
+
+	if cfg.Level != "" {
+		if _, err := ParseLevel(cfg.Level); err != nil {
+			return Config{}, err
+		}
+	}
+	if _, err := ParseLevelSpec(cfg.Levels); err != nil {
+		return Config{}, err
+	}
+	if cfg.Format != "" {
+		if _, err := ParseFormat(cfg.Format); err != nil {
+			return Config{}, err
+		}
+	}
+
+	cfg.Outputs = splitList(env("OUTPUTS"))
+
+	if tick := env("SAMPLING_TICK"); tick != "" {
+		sampling, err := samplingFromEnv(tick, env("SAMPLING_INITIAL"), env("SAMPLING_THEREAFTER"))
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Sampling = sampling
+	}
+
+	for _, pair := range splitList(env("FIELDS")) {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return Config{}, fmt.Errorf("invalid log field %q, expected key=value", pair)
+		}
+
+		if cfg.Fields == nil {
+			cfg.Fields = make(map[string]interface{})
+		}
+		cfg.Fields[key] = strings.TrimSpace(value)
+	}
+
+	return cfg, nil
+}
+
+// samplingFromEnv parses the sampling environment variables. Initial and thereafter default to 0.
+func samplingFromEnv(tick, initial, thereafter string) (*SamplingConfig, error) {
+	sampling := &SamplingConfig{}
+
+	err := sampling.Tick.UnmarshalText([]byte(tick))
+	if err != nil {
+		return nil, fmt.Errorf("invalid sampling tick: %w", err)
+	}
+	if initial != "" {
+		if sampling.Initial, err = strconv.Atoi(initial); err != nil {
+			return nil, fmt.Errorf("invalid sampling initial: %w", err)
+		}
+	}
+	if thereafter != "" {
+		if sampling.Thereafter, err = strconv.Atoi(thereafter); err != nil {
+			return nil, fmt.Errorf("invalid sampling thereafter: %w", err)
+		}
+	}
+
+	return sampling, nil
+}
+
+// release returns the Sentry release, defaulting to the service name@version.
+func (cfg Config) release() string {
+	if cfg.Sentry.Release != "" || cfg.Service.Name == "" || cfg.Service.Version == "" {
+		return cfg.Sentry.Release
+	}
+	return cfg.Service.Name + "@" + cfg.Service.Version
+}
+
+// fields returns the service and static fields, sorted by key.
+func (cfg Config) fields() []zapcore.Field {
+	fs := make([]zapcore.Field, 0, len(cfg.Fields)+1)
+	if cfg.Service.Name != "" {
+		fs = append(fs, fields.Service(cfg.Service.Name, cfg.Service.Version))
+	}
+
+	keys := make([]string, 0, len(cfg.Fields))
+	for key := range cfg.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fs = append(fs, zap.Any(key, cfg.Fields[key]))
+	}
+	return fs
+}
+
+// splitList splits a comma separated list, ignoring empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,108 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+func Test_NewFromConfig(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	var cfg log.Config
+	is.NoError(json.Unmarshal([]byte(`{
+		"level": "debug",
+		"levels": "db=warn",
+		"service": {"name": "myapp", "version": "v1.0.0"},
+		"fields": {"team": "core"}
+	}`), &cfg))
+
+	logger, err := log.NewFromConfig(cfg, log.WithOutput(&buf))
+	is.NoError(err)
+	logger.Debug(message)
+	logger.Named("db").Info(message)
+
+	var entry map[string]interface{}
+	is.NoError(json.Unmarshal(buf.Bytes(), &entry))
+	is.Equal("debug", entry["log.level"])
+	is.Equal(map[string]interface{}{"name": "myapp", "version": "v1.0.0"}, entry["service"])
+	is.Equal("core", entry["team"])
+}
+
+func Test_Config_SamplingTick(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	var cfg log.Config
+	is.NoError(json.Unmarshal([]byte(`{"sampling": {"initial": 1, "thereafter": 10, "tick": "1s"}}`), &cfg))
+	is.Equal(&log.SamplingConfig{Initial: 1, Thereafter: 10, Tick: log.Duration(time.Second)}, cfg.Sampling)
+
+	b, err := json.Marshal(cfg.Sampling)
+	is.NoError(err)
+	is.JSONEq(`{"initial": 1, "thereafter": 10, "tick": "1s"}`, string(b))
+
+	var sampling log.SamplingConfig
+	is.NoError(json.Unmarshal(b, &sampling))
+	is.Equal(*cfg.Sampling, sampling)
+
+	// Numbers are nanoseconds.
+	is.NoError(json.Unmarshal([]byte(`{"tick": 2000000000}`), &sampling))
+	is.Equal(log.Duration(2*time.Second), sampling.Tick)
+
+	is.Error(json.Unmarshal([]byte(`{"tick": "1 second"}`), &sampling))
+}
+
+func Test_NewFromConfig_Error(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	_, err := log.NewFromConfig(log.Config{Level: "verbose"})
+	is.ErrorIs(err, log.ErrInvalidLevel)
+
+	_, err = log.NewFromConfig(log.Config{Format: "yaml"})
+	is.ErrorIs(err, log.ErrInvalidFormat)
+
+	_, err = log.NewFromConfig(log.Config{Sentry: log.SentryConfig{DSN: "invalid"}})
+	is.Error(err)
+}
+
+//nolint:paralleltest // t.Setenv doesn't support parallel tests.
+func Test_ConfigFromEnv(t *testing.T) {
+	is := require.New(t)
+
+	t.Setenv("APP_LOG_LEVEL", "warn")
+	t.Setenv("APP_LOG_LEVELS", "db=debug")
+	t.Setenv("APP_LOG_FORMAT", "logfmt")
+	t.Setenv("APP_LOG_OUTPUTS", "stdout, /var/log/app.log")
+	t.Setenv("APP_LOG_SENTRY_DSN", "https://key@sentry.example.com/1")
+	t.Setenv("APP_LOG_SERVICE_NAME", "myapp")
+	t.Setenv("APP_LOG_SERVICE_VERSION", "v1.0.0")
+	t.Setenv("APP_LOG_FIELDS", "team=core,region=eu")
+
+	cfg, err := log.ConfigFromEnv("APP_LOG")
+	is.NoError(err)
+	is.Equal(log.Config{
+		Level:   "warn",
+		Levels:  "db=debug",
+		Format:  "logfmt",
+		Outputs: []string{"stdout", "/var/log/app.log"},
+		Sentry:  log.SentryConfig{DSN: "https://key@sentry.example.com/1"},
+		Service: log.ServiceConfig{Name: "myapp", Version: "v1.0.0"},
+		Fields:  map[string]interface{}{"team": "core", "region": "eu"},
+	}, cfg)
+
+	t.Setenv("APP_LOG_FIELDS", "team")
+	_, err = log.ConfigFromEnv("APP_LOG")
+	is.Error(err)
+
+	t.Setenv("APP_LOG_LEVEL", "verbose")
+	_, err = log.ConfigFromEnv("APP_LOG")
+	is.ErrorIs(err, log.ErrInvalidLevel)
+}
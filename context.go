@@ -0,0 +1,138 @@
+package log
+
+import (
+	"context"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/fields"
+	zapsentry "go.pixelfactory.io/pkg/observability/log/sentry"
+)
+
+// ContextLogger is a Logger able to enrich entries with request-scoped
+// fields stored in a context.Context.
+type ContextLogger interface {
+	Logger
+
+	DebugContext(ctx context.Context, msg string, fields ...zapcore.Field)
+	InfoContext(ctx context.Context, msg string, fields ...zapcore.Field)
+	WarnContext(ctx context.Context, msg string, fields ...zapcore.Field)
+	ErrorContext(ctx context.Context, msg string, fields ...zapcore.Field)
+	FatalContext(ctx context.Context, msg string, fields ...zapcore.Field)
+	PanicContext(ctx context.Context, msg string, fields ...zapcore.Field)
+}
+
+// loggerContextKey is the context key holding a *DefaultLogger.
+type loggerContextKey struct{}
+
+// fieldsContextKey is the context key holding request-scoped fields.
+type fieldsContextKey struct{}
+
+// WithContext returns a copy of ctx carrying the given logger.
+func WithContext(ctx context.Context, logger *DefaultLogger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by WithContext.
+// The default logger is returned when ctx doesn't carry any logger, see L.
+func FromContext(ctx context.Context) *DefaultLogger {
+	if l, ok := ctx.Value(loggerContextKey{}).(*DefaultLogger); ok && l != nil {
+		return l
+	}
+
+	return L()
+}
+
+// ContextWithFields returns a copy of ctx carrying request-scoped fields
+// (request ID, trace IDs, user...). Fields are appended to the ones already
+// stored in ctx and are added to every entry logged through the *Context methods.
+func ContextWithFields(ctx context.Context, fields ...zapcore.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	existing := FieldsFromContext(ctx)
+	merged := make([]zapcore.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+
+	return context.WithValue(ctx, fieldsContextKey{}, merged)
+}
+
+// FieldsFromContext returns the request-scoped fields stored in ctx.
+func FieldsFromContext(ctx context.Context) []zapcore.Field {
+	if fields, ok := ctx.Value(fieldsContextKey{}).([]zapcore.Field); ok {
+		return fields
+	}
+	return nil
+}
+
+// contextFields returns the request-scoped fields and Sentry hub of ctx followed by the given fields.
+func (l *DefaultLogger) contextFields(ctx context.Context, fs []zapcore.Field) []zapcore.Field {
+	ctxFields := FieldsFromContext(ctx)
+	var hub *sentry.Hub
+	if l.sentry {
+		hub = sentry.GetHubFromContext(ctx)
+	}
+	if len(ctxFields) == 0 && !l.traceCorrelation && hub == nil {
+		return fs
+	}
+
+	merged := make([]zapcore.Field, 0, len(ctxFields)+len(fs)+2)
+	merged = append(merged, ctxFields...)
+	if hub != nil {
+		merged = append(merged, zapsentry.Hub(hub))
+	}
+	if l.traceCorrelation {
+		if trace := fields.Trace(ctx); trace.Type != zapcore.SkipType {
+			merged = append(merged, trace)
+		}
+	}
+	return append(merged, fs...)
+}
+
+// DebugContext logs a debug msg with the request-scoped fields of ctx and fields.
+func (l *DefaultLogger) DebugContext(ctx context.Context, msg string, fields ...zapcore.Field) {
+	l.logger.Debug(msg, l.contextFields(ctx, fields)...)
+}
+
+// InfoContext logs an info msg with the request-scoped fields of ctx and fields.
+func (l *DefaultLogger) InfoContext(ctx context.Context, msg string, fields ...zapcore.Field) {
+	l.logger.Info(msg, l.contextFields(ctx, fields)...)
+}
+
+// WarnContext logs a warning msg with the request-scoped fields of ctx and fields.
+func (l *DefaultLogger) WarnContext(ctx context.Context, msg string, fields ...zapcore.Field) {
+	l.logger.Warn(msg, l.contextFields(ctx, fields)...)
+}
+
+// ErrorContext logs an error msg with the request-scoped fields of ctx and fields.
+func (l *DefaultLogger) ErrorContext(ctx context.Context, msg string, fields ...zapcore.Field) {
+	l.logger.Error(msg, l.contextFields(ctx, fields)...)
+}
+
+// FatalContext logs a fatal error msg with the request-scoped fields of ctx and fields and panics.
+// Use WithFatalBehavior to exit instead.
+func (l *DefaultLogger) FatalContext(ctx context.Context, msg string, fields ...zapcore.Field) {
+	if l.fatal != nil {
+		l.logger.Fatal(msg, l.contextFields(ctx, fields)...)
+		return
+	}
+	// Calls panic, as zap.Fatal calls os.Exit and isn't recoverable.
+	l.logger.Panic(msg, l.contextFields(ctx, fields)...)
+}
+
+// PanicContext logs a fatal error msg with the request-scoped fields of ctx and fields and panics.
+func (l *DefaultLogger) PanicContext(ctx context.Context, msg string, fields ...zapcore.Field) {
+	l.logger.Panic(msg, l.contextFields(ctx, fields)...)
+}
+
+// LogContext logs a msg with the request-scoped fields of ctx and fields at the given level.
+// Like FatalContext, the fatal level panics instead of exiting, unless WithFatalBehavior is used.
+func (l *DefaultLogger) LogContext(ctx context.Context, level zapcore.Level, msg string, fields ...zapcore.Field) {
+	if level == zapcore.FatalLevel && l.fatal == nil {
+		level = zapcore.PanicLevel
+	}
+	l.logger.Log(level, msg, l.contextFields(ctx, fields)...)
+}
@@ -0,0 +1,179 @@
+package log_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+func Test_FromContext(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, _ := setupLogger()
+
+	ctx := log.WithContext(context.Background(), logger)
+
+	is.Same(logger, log.FromContext(ctx))
+	is.Implements((*log.ContextLogger)(nil), logger)
+}
+
+func Test_FromContext_Empty(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	logger := log.FromContext(context.Background())
+
+	is.NotNil(logger)
+	is.NotPanics(func() {
+		logger.Info(message)
+	})
+}
+
+func Test_ContextWithFields(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	ctx := log.ContextWithFields(context.Background(), zap.String("request.id", "1"))
+	ctx = log.ContextWithFields(ctx, zap.String("user.id", "2"))
+
+	is.Equal([]zapcore.Field{
+		zap.String("request.id", "1"),
+		zap.String("user.id", "2"),
+	}, log.FieldsFromContext(ctx))
+}
+
+func Test_InfoContext(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	ctx := log.ContextWithFields(context.Background(), zap.String("request.id", "1"))
+	logger.InfoContext(ctx, message, zap.String(testKey, testValue))
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(zap.InfoLevel, entry.Level)
+		is.Equal(message, entry.Message)
+		is.Equal([]zapcore.Field{
+			zap.String("request.id", "1"),
+			zap.String(testKey, testValue),
+		}, entry.Context)
+	}
+}
+
+func Test_ContextLevels(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+	ctx := context.Background()
+
+	logger.DebugContext(ctx, message)
+	logger.WarnContext(ctx, message)
+	logger.ErrorContext(ctx, message)
+	is.Panics(func() {
+		logger.PanicContext(ctx, message)
+	})
+	is.Panics(func() {
+		logger.FatalContext(ctx, message)
+	})
+	logger.LogContext(ctx, zap.InfoLevel, message)
+	is.Panics(func() {
+		logger.LogContext(ctx, zap.FatalLevel, message)
+	})
+
+	levels := make([]zapcore.Level, 0, logs.Len())
+	for _, entry := range logs.All() {
+		levels = append(levels, entry.Level)
+	}
+	is.Equal([]zapcore.Level{
+		zap.DebugLevel,
+		zap.WarnLevel,
+		zap.ErrorLevel,
+		zap.PanicLevel,
+		zap.PanicLevel,
+		zap.InfoLevel,
+		zap.PanicLevel,
+	}, levels)
+}
+
+func Test_LogContext(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	ctx := log.ContextWithFields(context.Background(), zap.String("request.id", "1"))
+	logger.LogContext(ctx, zap.WarnLevel, message, zap.String(testKey, testValue))
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(zap.WarnLevel, entry.Level)
+		is.Equal([]zapcore.Field{
+			zap.String("request.id", "1"),
+			zap.String(testKey, testValue),
+		}, entry.Context)
+	}
+}
+
+func Test_WithTraceCorrelation(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	obsOpts, logs := setupObserver(level)
+	logger := log.New(log.WithTraceCorrelation(), log.WithZapOption(obsOpts))
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	is.NoError(err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	is.NoError(err)
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	logger.InfoContext(ctx, message)
+	logger.InfoContext(context.Background(), message)
+
+	if logs.Len() != 2 {
+		t.Errorf("No logs")
+	} else {
+		is.Equal([]zapcore.Field{fields.Trace(ctx)}, logs.All()[0].Context)
+		is.Empty(logs.All()[1].Context)
+	}
+}
+
+func Test_ErrorContext_SentryHub(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	tr := &transport{}
+
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.test/1", Transport: tr})
+	is.NoError(err)
+	logger, err := log.Build(log.WithOutput(io.Discard), log.WithSentry(client))
+	is.NoError(err)
+
+	hub := sentry.NewHub(client, sentry.NewScope())
+	hub.Scope().SetUser(sentry.User{ID: "42"})
+	ctx := sentry.SetHubOnContext(context.Background(), hub)
+
+	logger.ErrorContext(ctx, message)
+	logger.Error(message)
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	is.Len(tr.events, 2)
+	is.Equal("42", tr.events[0].User.ID)
+	is.Empty(tr.events[1].User.ID)
+}
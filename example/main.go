@@ -13,6 +13,7 @@ import (
 
 	"go.pixelfactory.io/pkg/observability/log"
 	"go.pixelfactory.io/pkg/observability/log/fields"
+	"go.pixelfactory.io/pkg/observability/log/middleware"
 )
 
 func main() {
@@ -50,7 +51,11 @@ func main() {
 	// Fields must be added after logger creation
 	logger = logger.With(fields.Service("myapp", "v1.0"))
 
-	client := http.Client{Timeout: 1 * time.Second}
+	// Outbound requests are logged by the transport
+	client := http.Client{
+		Timeout:   1 * time.Second,
+		Transport: middleware.NewTransport(logger, http.DefaultTransport),
+	}
 	request, err := http.NewRequestWithContext(
 		context.Background(),
 		http.MethodGet,
@@ -68,7 +73,4 @@ func main() {
 	}
 
 	defer func() { _ = response.Body.Close() }()
-
-	logger.Info("Sent Http Request", fields.HTTPRequest(request))
-	logger.Info("Got Http Response", fields.HTTPResponse(response))
 }
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FatalBehavior is called by Fatal once the fatal entry is written, and the logger synced,
+// Sentry events included, see WithFatalBehavior.
+type FatalBehavior func(entry zapcore.Entry)
+
+// FatalPanic is a FatalBehavior panicking with the entry message, which deferred recovers can stop.
+func FatalPanic(entry zapcore.Entry) {
+	panic(entry.Message)
+}
+
+// FatalExit is a FatalBehavior terminating the program with os.Exit(1).
+// Deferred functions aren't run, and the logger isn't closed, but its outputs are synced.
+func FatalExit(zapcore.Entry) {
+	os.Exit(1)
+}
+
+// FatalHook returns a FatalBehavior calling hook. Fatal returns once hook returns.
+func FatalHook(hook func(entry zapcore.Entry)) FatalBehavior {
+	return hook
+}
+
+// WithFatalBehavior sets what Fatal does once the entry is written, logged at the fatal level.
+// Without this option, Fatal logs at the panic level and panics like Panic.
+func WithFatalBehavior(behavior FatalBehavior) Option {
+	return func(l *DefaultLogger) {
+		l.fatal = behavior
+		l.config.addZapOptions(zap.WithFatalHook(&fatalHook{logger: l, behavior: behavior}))
+	}
+}
+
+// fatalHook syncs the logger before calling a FatalBehavior.
+type fatalHook struct {
+	logger   *DefaultLogger
+	behavior FatalBehavior
+}
+
+// OnWrite implements zapcore.CheckWriteHook.
+func (h *fatalHook) OnWrite(entry *zapcore.CheckedEntry, _ []zapcore.Field) {
+	_ = h.logger.Sync()
+	h.behavior(entry.Entry)
+}
@@ -0,0 +1,68 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+// fatalExitEnv makes Test_FatalExit exit with log.FatalExit, in a subprocess.
+const fatalExitEnv = "LOG_TEST_FATAL_EXIT"
+
+func Test_WithFatalBehavior(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	var entries []zapcore.Entry
+	logger, err := log.Build(
+		log.WithOutput(&buf),
+		log.WithAsync(10, time.Hour),
+		log.WithFatalBehavior(log.FatalHook(func(entry zapcore.Entry) {
+			// The entry is flushed before the hook is called.
+			is.Contains(buf.String(), entry.Message)
+			entries = append(entries, entry)
+		})),
+	)
+	is.NoError(err)
+
+	logger.Fatal("fatal")
+	logger.With().Log(zapcore.FatalLevel, "log")
+	is.Len(entries, 2)
+	is.Equal(zapcore.FatalLevel, entries[0].Level)
+	is.Equal("log", entries[1].Message)
+
+	logger, err = log.Build(log.WithOutput(&buf), log.WithFatalBehavior(log.FatalPanic))
+	is.NoError(err)
+	is.PanicsWithValue("fatal", func() { logger.Fatal("fatal") })
+}
+
+func Test_FatalExit(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	if os.Getenv(fatalExitEnv) != "" {
+		logger := log.New(log.WithAsync(10, time.Hour), log.WithFatalBehavior(log.FatalExit))
+		logger.Info("queued")
+		logger.Fatal("exiting")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^Test_FatalExit$")
+	cmd.Env = append(os.Environ(), fatalExitEnv+"=1")
+	out, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	is.True(errors.As(err, &exitErr))
+	is.Equal(1, exitErr.ExitCode())
+	is.Contains(string(out), `"message":"queued"`)
+	is.Contains(string(out), `"message":"exiting"`)
+}
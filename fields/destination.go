@@ -0,0 +1,33 @@
+package fields
+
+import (
+	"net"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DestinationField struct represents ECS destination object
+// https://www.elastic.co/guide/en/ecs/current/ecs-destination.html
+type DestinationField struct {
+	Address string
+	Port    int
+}
+
+// MarshalLogObject implements zapcore ObjectMarshaler.
+func (d *DestinationField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("address", d.Address)
+	if net.ParseIP(d.Address) != nil {
+		enc.AddString("ip", d.Address)
+	} else {
+		enc.AddString("domain", d.Address)
+	}
+	enc.AddInt("port", d.Port)
+	return nil
+}
+
+// Destination returns ECS destination as zap.Field
+// https://www.elastic.co/guide/en/ecs/current/ecs-destination.html
+func Destination(address string, port int) zapcore.Field {
+	return zap.Object("destination", &DestinationField{Address: address, Port: port})
+}
@@ -0,0 +1,32 @@
+package fields_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+func Test_Destination(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	destination := fields.Destination("example.com", 443)
+	is.NotEmpty(destination)
+	is.Equal(destination, zap.Object("destination", &fields.DestinationField{Address: "example.com", Port: 443}))
+}
+
+func Test_DestinationField_IP(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	enc := zapcore.NewMapObjectEncoder()
+	is.NoError((&fields.DestinationField{Address: "10.0.0.1", Port: 8080}).MarshalLogObject(enc))
+	is.Equal(map[string]interface{}{
+		"address": "10.0.0.1",
+		"ip":      "10.0.0.1",
+		"port":    8080,
+	}, enc.Fields)
+}
@@ -0,0 +1,27 @@
+package fields
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// EventField struct represents ECS event object
+// https://www.elastic.co/guide/en/ecs/current/ecs-event.html
+type EventField struct {
+	Duration time.Duration
+}
+
+// MarshalLogObject implements zapcore ObjectMarshaler.
+func (e *EventField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	// ECS event.duration is expressed in nanoseconds.
+	enc.AddInt64("duration", e.Duration.Nanoseconds())
+	return nil
+}
+
+// EventDuration returns ECS event.duration as zap.Field
+// https://www.elastic.co/guide/en/ecs/current/ecs-event.html
+func EventDuration(d time.Duration) zapcore.Field {
+	return zap.Object("event", &EventField{Duration: d})
+}
@@ -0,0 +1,24 @@
+package fields_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+func Test_EventDuration(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	event := fields.EventDuration(2 * time.Millisecond)
+	is.NotEmpty(event)
+	is.Equal(event, zap.Object("event", &fields.EventField{Duration: 2 * time.Millisecond}))
+
+	enc := zapcore.NewMapObjectEncoder()
+	is.NoError(event.Interface.(zapcore.ObjectMarshaler).MarshalLogObject(enc))
+	is.Equal(int64(2000000), enc.Fields["duration"])
+}
@@ -8,8 +8,10 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"go.pixelfactory.io/pkg/observability/log/fields"
+	"go.pixelfactory.io/pkg/observability/log/redact"
 )
 
 func Test_HTTPRequest(t *testing.T) {
@@ -43,3 +45,35 @@ func Test_HTTPResponse(t *testing.T) {
 	is.NotEmpty(respField)
 	is.Equal(respField, zap.Object("http.response", &fields.HTTPResponseField{Response: resp}))
 }
+
+func Test_HTTPResponseField_BodyBytes(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	field := &fields.HTTPResponseField{
+		Response:  &http.Response{StatusCode: http.StatusOK, ContentLength: 42},
+		BodyBytes: 42,
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	is.NoError(field.MarshalLogObject(enc))
+	is.Equal(map[string]interface{}{
+		"status_code": http.StatusOK,
+		"bytes":       int64(42),
+		"body": map[string]interface{}{
+			"bytes": int64(42),
+		},
+	}, enc.Fields)
+}
+
+func Test_HTTPRequestField_Headers(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	req := httptest.NewRequest(http.MethodGet, "http://test/foo", http.NoBody)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	enc := zapcore.NewMapObjectEncoder()
+	is.NoError((&fields.HTTPRequestField{Request: req}).MarshalLogObject(enc))
+	is.Equal(http.Header{"Authorization": {redact.Masked}}, enc.Fields["headers"])
+	is.Equal("Bearer secret", req.Header.Get("Authorization"))
+}
@@ -6,12 +6,16 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/redact"
 )
 
 // HTTPRequestField struct represents ECS http.request object
 // https://www.elastic.co/guide/en/ecs/current/ecs-http.html
 type HTTPRequestField struct {
 	Request *http.Request
+	// BodyContent is the (possibly truncated) request body, reported as body.content when set.
+	BodyContent string
 }
 
 // MarshalLogObject implements zapcore ObjectMarshaler.
@@ -25,9 +29,16 @@ func (r *HTTPRequestField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	enc.AddString("referrer", r.Request.Referer())
 	enc.AddInt64("bytes", r.Request.ContentLength)
 
-	if err := enc.AddReflected("headers", r.Request.Header); err != nil {
+	// Credentials are masked by default.
+	if err := enc.AddReflected("headers", redact.Headers(r.Request.Header)); err != nil {
 		return err
 	}
+	if r.BodyContent != "" {
+		return enc.AddObject("body", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("content", r.BodyContent)
+			return nil
+		}))
+	}
 	return nil
 }
 
@@ -11,12 +11,31 @@ import (
 // https://www.elastic.co/guide/en/ecs/current/ecs-http.html
 type HTTPResponseField struct {
 	Response *http.Response
+	// BodyBytes is the size of the response body, reported as body.bytes when set.
+	BodyBytes int64
+	// BodyContent is the (possibly truncated) response body, reported as body.content when set.
+	BodyContent string
 }
 
 // MarshalLogObject implements zapcore ObjectMarshaler.
 func (r *HTTPResponseField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if r.Response == nil {
+		return nil
+	}
+
 	enc.AddInt("status_code", r.Response.StatusCode)
 	enc.AddInt64("bytes", r.Response.ContentLength)
+	if r.BodyBytes > 0 || r.BodyContent != "" {
+		return enc.AddObject("body", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			if r.BodyBytes > 0 {
+				enc.AddInt64("bytes", r.BodyBytes)
+			}
+			if r.BodyContent != "" {
+				enc.AddString("content", r.BodyContent)
+			}
+			return nil
+		}))
+	}
 	return nil
 }
 
@@ -0,0 +1,25 @@
+package fields
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FingerprintKey is the key of the Fingerprint field.
+const FingerprintKey = "sentry.fingerprint"
+
+// TransactionKey is the key of the SentryTransaction field.
+const TransactionKey = "sentry.transaction"
+
+// Fingerprint returns the Sentry fingerprint of the entry as zap.Field, grouping the events
+// with the same parts. The "{{ default }}" part extends the default grouping.
+// https://docs.sentry.io/platforms/go/usage/sdk-fingerprinting/
+func Fingerprint(parts ...string) zapcore.Field {
+	return zap.Strings(FingerprintKey, parts)
+}
+
+// SentryTransaction returns the name of the Sentry event transaction as zap.Field,
+// e.g. the route of the request being handled.
+func SentryTransaction(name string) zapcore.Field {
+	return zap.String(TransactionKey, name)
+}
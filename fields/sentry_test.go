@@ -0,0 +1,22 @@
+package fields_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+func Test_Fingerprint(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	is.Equal(zap.Strings("sentry.fingerprint", []string{"db", "timeout"}), fields.Fingerprint("db", "timeout"))
+}
+
+func Test_SentryTransaction(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	is.Equal(zap.String("sentry.transaction", "GET /users"), fields.SentryTransaction("GET /users"))
+}
@@ -10,12 +10,20 @@ import (
 type ServiceField struct {
 	Name    string
 	Version string
+	// NodeName is the service.node.name of the instance, e.g. the host or pod name. Omitted when empty.
+	NodeName string
 }
 
 // MarshalLogObject implements zapcore ObjectMarshaler.
 func (s *ServiceField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	enc.AddString("name", s.Name)
 	enc.AddString("version", s.Version)
+	if s.NodeName != "" {
+		return enc.AddObject("node", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("name", s.NodeName)
+			return nil
+		}))
+	}
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"go.pixelfactory.io/pkg/observability/log/fields"
 )
@@ -16,3 +17,16 @@ func Test_Service(t *testing.T) {
 	is.NotEmpty(service)
 	is.Equal(service, zap.Object("service", &fields.ServiceField{Name: "testSvc", Version: "0.0.1"}))
 }
+
+func Test_Service_NodeName(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	enc := zapcore.NewMapObjectEncoder()
+
+	is.NoError((&fields.ServiceField{Name: "testSvc", Version: "0.0.1", NodeName: "pod-1"}).MarshalLogObject(enc))
+	is.Equal(map[string]interface{}{
+		"name":    "testSvc",
+		"version": "0.0.1",
+		"node":    map[string]interface{}{"name": "pod-1"},
+	}, enc.Fields)
+}
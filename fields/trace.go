@@ -0,0 +1,45 @@
+package fields
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceField struct represents ECS trace, span and transaction identifiers
+// https://www.elastic.co/guide/en/ecs/current/ecs-tracing.html
+type TraceField struct {
+	TraceID string
+	SpanID  string
+	// TransactionID is the ID of the root span of the service, omitted when empty.
+	TransactionID string
+}
+
+// MarshalLogObject implements zapcore ObjectMarshaler.
+func (t *TraceField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("trace.id", t.TraceID)
+	enc.AddString("span.id", t.SpanID)
+	if t.TransactionID != "" {
+		enc.AddString("transaction.id", t.TransactionID)
+	}
+	return nil
+}
+
+// Trace returns ECS trace.id and span.id of the OpenTelemetry span active in ctx
+// as an inlined zap.Field. The transaction.id is omitted, as the active span may be
+// a child span: set TraceField.TransactionID to the root span ID instead.
+// A skipped field is returned when ctx doesn't carry a valid span context.
+// https://www.elastic.co/guide/en/ecs/current/ecs-tracing.html
+func Trace(ctx context.Context) zapcore.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return zap.Skip()
+	}
+
+	return zap.Inline(&TraceField{
+		TraceID: spanContext.TraceID().String(),
+		SpanID:  spanContext.SpanID().String(),
+	})
+}
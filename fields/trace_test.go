@@ -0,0 +1,55 @@
+package fields_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+func Test_Trace(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	is.NoError(err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	is.NoError(err)
+
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	traceField := fields.Trace(ctx)
+	is.NotEmpty(traceField)
+	is.Equal(traceField, zap.Inline(&fields.TraceField{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	}))
+
+	enc := zapcore.NewMapObjectEncoder()
+	traceField.AddTo(enc)
+	is.NotContains(enc.Fields, "transaction.id")
+}
+
+func Test_TraceField_TransactionID(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	enc := zapcore.NewMapObjectEncoder()
+
+	is.NoError((&fields.TraceField{TraceID: "1", SpanID: "2", TransactionID: "3"}).MarshalLogObject(enc))
+	is.Equal(map[string]interface{}{"trace.id": "1", "span.id": "2", "transaction.id": "3"}, enc.Fields)
+}
+
+func Test_Trace_NoSpan(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	is.Equal(zap.Skip(), fields.Trace(context.Background()))
+}
@@ -2,6 +2,7 @@ package fields
 
 import (
 	"net/url"
+	"strconv"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -14,7 +15,20 @@ type URLField struct {
 }
 
 // MarshalLogObject implements zapcore ObjectMarshaler.
+// Absolute URLs, as used by outbound requests, also report full, scheme, domain and port.
 func (u *URLField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if u.URL == nil {
+		return nil
+	}
+
+	if u.URL.Host != "" {
+		enc.AddString("full", u.URL.Redacted())
+		enc.AddString("scheme", u.URL.Scheme)
+		enc.AddString("domain", u.URL.Hostname())
+		if port, err := strconv.Atoi(u.URL.Port()); err == nil {
+			enc.AddInt("port", port)
+		}
+	}
 	enc.AddString("path", u.URL.Path)
 	enc.AddString("query", u.URL.RawQuery)
 	return nil
@@ -0,0 +1,143 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.elastic.co/ecszap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/logfmt"
+)
+
+// Format is the encoding of log entries.
+type Format string
+
+const (
+	// FormatECSJSON encodes entries as ECS JSON, the default format.
+	FormatECSJSON Format = "ecs-json"
+	// FormatConsole encodes entries as human-readable lines, colorized when written to a terminal.
+	FormatConsole Format = "console"
+	// FormatLogfmt encodes entries as logfmt, with ECS dotted keys, e.g. http.request.method=GET.
+	FormatLogfmt Format = "logfmt"
+	// FormatAuto selects the format from the FormatEnv environment variable, or
+	// FormatConsole when the output is a terminal and FormatECSJSON otherwise.
+	FormatAuto Format = "auto"
+)
+
+const (
+	// FormatEnv is the environment variable read by FormatAuto.
+	FormatEnv = "LOG_FORMAT"
+	// noColorEnv disables colors when set, see https://no-color.org.
+	noColorEnv = "NO_COLOR"
+)
+
+// ErrInvalidFormat is returned when a format name is unknown.
+var ErrInvalidFormat = errors.New("invalid log format")
+
+// ParseFormat returns the Format matching a format name.
+// Names are case-insensitive, and "json" is an alias of "ecs-json".
+func ParseFormat(format string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "ecs-json", "json":
+		return FormatECSJSON, nil
+	case "console":
+		return FormatConsole, nil
+	case "logfmt":
+		return FormatLogfmt, nil
+	case "auto", "":
+		return FormatAuto, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrInvalidFormat, format)
+}
+
+// WithFormat sets the encoding of log entries, see Format.
+// An unknown format makes Build return an error.
+func WithFormat(format string) Option {
+	return func(l *DefaultLogger) {
+		f, err := ParseFormat(format)
+		if err != nil {
+			l.config.errs = append(l.config.errs, err)
+			return
+		}
+		l.config.format = f
+	}
+}
+
+// resolveFormat returns the format of the entries written to the output.
+func (c *config) resolveFormat() (Format, error) {
+	if c.format != FormatAuto {
+		return c.format, nil
+	}
+
+	if env, ok := os.LookupEnv(FormatEnv); ok && strings.TrimSpace(env) != "" {
+		f, err := ParseFormat(env)
+		if err != nil || f != FormatAuto {
+			return f, err
+		}
+	}
+
+	if c.terminal {
+		return FormatConsole, nil
+	}
+	return FormatECSJSON, nil
+}
+
+// newEncoderCore returns the core encoding entries to the output, or to async when set.
+func (c *config) newEncoderCore(async *asyncWriter) (zapcore.Core, error) {
+	format, err := c.resolveFormat()
+	if err != nil {
+		// Fall back to the default format.
+		format = FormatECSJSON
+	}
+
+	newCore := func(enc zapcore.Encoder) zapcore.Core {
+		if async != nil {
+			return &asyncCore{LevelEnabler: zapcore.DebugLevel, enc: enc, writer: async}
+		}
+		return zapcore.NewCore(enc, c.output, zapcore.DebugLevel)
+	}
+
+	ecsConfig := ecszap.NewDefaultEncoderConfig().ToZapCoreEncoderConfig()
+
+	switch format {
+	case FormatConsole:
+		color := c.terminal && os.Getenv(noColorEnv) == ""
+		return newCore(zapcore.NewConsoleEncoder(consoleEncoderConfig(color))), err
+	case FormatLogfmt:
+		// WrapCore renders errors as ECS error objects, as in the JSON format.
+		return ecszap.WrapCore(newCore(logfmt.NewEncoder(ecsConfig))), err
+	case FormatECSJSON, FormatAuto:
+	}
+
+	// Same as ecszap.NewCore.
+	return ecszap.WrapCore(newCore(zapcore.NewJSONEncoder(ecsConfig))), err
+}
+
+// consoleEncoderConfig returns the console encoder configuration.
+// Structured fields, including nested ECS objects, are rendered as compact JSON after the message.
+func consoleEncoderConfig(color bool) zapcore.EncoderConfig {
+	cfg := zap.NewDevelopmentEncoderConfig()
+	cfg.EncodeTime = zapcore.TimeEncoderOfLayout("15:04:05.000")
+	cfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	if color {
+		cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	return cfg
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,86 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pixelfactory.io/pkg/observability/log"
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+func Test_WithFormat_Console(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger := log.New(log.WithFormat("console"), log.WithOutput(&buf))
+	logger.Info(message, fields.Service("myapp", "v1.0.0"))
+
+	is.Contains(buf.String(), "\tINFO\t")
+	is.Contains(buf.String(), message)
+	is.Contains(buf.String(), `{"service": {"name": "myapp", "version": "v1.0.0"}}`)
+	is.NotContains(buf.String(), "\x1b[")
+}
+
+func Test_WithFormat_Logfmt(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger := log.New(log.WithFormat("logfmt"), log.WithOutput(&buf))
+	logger.Named("db").Error(message, fields.Service("myapp", "v1.0.0"), fields.Error(errors.New("failed")))
+
+	is.Contains(buf.String(), "log.level=error ")
+	is.Contains(buf.String(), "log.logger=db ")
+	is.Regexp(`log\.origin\.file\.name=\S*format_test\.go `, buf.String())
+	is.Contains(buf.String(), `message="test log message" `)
+	is.Contains(buf.String(), "service.name=myapp service.version=v1.0.0 ")
+	is.Contains(buf.String(), "error.message=failed ")
+}
+
+//nolint:paralleltest // t.Setenv doesn't support parallel tests.
+func Test_WithFormat_Auto(t *testing.T) {
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	t.Setenv(log.FormatEnv, "")
+	log.New(log.WithFormat("auto"), log.WithOutput(&buf)).Info(message)
+	is.True(json.Valid(buf.Bytes()))
+
+	buf.Reset()
+	t.Setenv(log.FormatEnv, "console")
+	log.New(log.WithFormat("auto"), log.WithOutput(&buf)).Info(message)
+	is.Contains(buf.String(), "\tINFO\t")
+
+	t.Setenv(log.FormatEnv, "yaml")
+	_, err := log.Build(log.WithFormat("auto"))
+	is.ErrorIs(err, log.ErrInvalidFormat)
+}
+
+func Test_ParseFormat(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	formats := map[string]log.Format{
+		"ecs-json": log.FormatECSJSON,
+		"JSON":     log.FormatECSJSON,
+		"console":  log.FormatConsole,
+		" logfmt ": log.FormatLogfmt,
+		"":         log.FormatAuto,
+	}
+	for name, expected := range formats {
+		format, err := log.ParseFormat(name)
+		is.NoError(err)
+		is.Equal(expected, format)
+	}
+
+	_, err := log.ParseFormat("yaml")
+	is.ErrorIs(err, log.ErrInvalidFormat)
+
+	_, err = log.Build(log.WithFormat("yaml"))
+	is.ErrorIs(err, log.ErrInvalidFormat)
+}
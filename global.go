@@ -0,0 +1,100 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// global holds the default logger, and its copy used by the package-level functions.
+type global struct {
+	logger *DefaultLogger
+	// caller skips the package-level function frame.
+	caller *DefaultLogger
+}
+
+// defaultLogger is the logger set by SetDefault.
+//
+//nolint:gochecknoglobals // The default logger is process-wide, like zap.L().
+var defaultLogger atomic.Pointer[global]
+
+// SetDefault makes l the default logger, returned by L and used by the package-level functions
+// and FromContext. It also replaces the zap global loggers, so that zap.L() and zap.S() log through l.
+// A nil l restores the no-op default logger.
+func SetDefault(l *DefaultLogger) {
+	if l == nil {
+		l = newNopLogger()
+	}
+
+	caller := l.clone()
+	caller.logger = l.logger.WithOptions(zap.AddCallerSkip(1))
+	defaultLogger.Store(&global{logger: l, caller: caller})
+
+	// zap loggers are called directly, without the DefaultLogger methods frame.
+	zap.ReplaceGlobals(l.logger.WithOptions(zap.AddCallerSkip(-1)))
+}
+
+// L returns the default logger, a no-op logger unless SetDefault was called.
+func L() *DefaultLogger {
+	return loadDefault().logger
+}
+
+// RedirectStdLog redirects the output of the standard library log package to the default logger
+// at level, and returns a function restoring the previous output, like zap.RedirectStdLogAt.
+// The default logger is the one returned by L when it's called, later SetDefault calls don't change it.
+// Use DefaultLogger.RedirectStdLog to redirect the output to another logger.
+func RedirectStdLog(level zapcore.Level) (func(), error) {
+	return L().RedirectStdLog(level)
+}
+
+// Debug logs a debug msg with fields to the default logger.
+func Debug(msg string, fields ...zapcore.Field) {
+	loadDefault().caller.Debug(msg, fields...)
+}
+
+// Info logs an info msg with fields to the default logger.
+func Info(msg string, fields ...zapcore.Field) {
+	loadDefault().caller.Info(msg, fields...)
+}
+
+// Warn logs a warning msg with fields to the default logger.
+func Warn(msg string, fields ...zapcore.Field) {
+	loadDefault().caller.Warn(msg, fields...)
+}
+
+// Error logs an error msg with fields to the default logger.
+func Error(msg string, fields ...zapcore.Field) {
+	loadDefault().caller.Error(msg, fields...)
+}
+
+// Fatal logs a fatal error msg with fields to the default logger, see DefaultLogger.Fatal.
+func Fatal(msg string, fields ...zapcore.Field) {
+	loadDefault().caller.Fatal(msg, fields...)
+}
+
+// Panic logs a fatal error msg with fields to the default logger and panics.
+func Panic(msg string, fields ...zapcore.Field) {
+	loadDefault().caller.Panic(msg, fields...)
+}
+
+// loadDefault returns the default logger, or a no-op one when SetDefault wasn't called.
+func loadDefault() *global {
+	if g := defaultLogger.Load(); g != nil {
+		return g
+	}
+
+	nop := newNopLogger()
+	return &global{logger: nop, caller: nop}
+}
+
+// newNopLogger returns a logger discarding entries.
+func newNopLogger() *DefaultLogger {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	return &DefaultLogger{
+		logger:  zap.NewNop(),
+		level:   &level,
+		levels:  newLevelRegistry(&level),
+		closers: &closers{},
+	}
+}
@@ -0,0 +1,65 @@
+package log_test
+
+import (
+	"context"
+	stdlog "log" //nolint:depguard // The standard library log output is redirected.
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+//nolint:paralleltest // SetDefault replaces process-wide loggers.
+func Test_SetDefault(t *testing.T) {
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	log.SetDefault(logger)
+	t.Cleanup(func() { log.SetDefault(nil) })
+
+	is.Same(logger, log.L())
+	is.Same(logger, log.FromContext(context.Background()))
+
+	log.Info(message)
+	zap.L().Warn(message)
+	zap.S().Error(message)
+	is.Panics(func() { log.Panic(message) })
+
+	restore, err := log.RedirectStdLog(zapcore.InfoLevel)
+	is.NoError(err)
+	stdlog.Print(message)
+	restore()
+
+	entries := logs.TakeAll()
+	is.Len(entries, 5)
+	for _, entry := range entries {
+		is.Equal(message, entry.Message)
+		is.Equal("global_test.go", filepath.Base(entry.Caller.File))
+	}
+	is.Equal(zapcore.WarnLevel, entries[1].Level)
+
+	log.SetDefault(nil)
+	log.Info(message)
+	is.Zero(logs.Len())
+}
+
+//nolint:paralleltest // The standard library log output is process-wide.
+func Test_RedirectStdLog(t *testing.T) {
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	// The logger doesn't need to be the default logger.
+	restore, err := logger.RedirectStdLog(zapcore.WarnLevel)
+	is.NoError(err)
+	stdlog.Print(message)
+	restore()
+
+	entries := logs.TakeAll()
+	is.Len(entries, 1)
+	is.Equal(zapcore.WarnLevel, entries[0].Level)
+	is.Equal(message, entries[0].Message)
+}
@@ -7,15 +7,18 @@ toolchain go1.24.12
 require (
 	github.com/getsentry/sentry-go v0.42.0
 	github.com/mssola/user_agent v0.6.0
-	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.11.1
 	go.elastic.co/ecszap v1.0.3
+	go.opentelemetry.io/otel/trace v1.40.0
 	go.uber.org/zap v1.27.1
 )
 
 require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel v1.40.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/sys v0.40.0 // indirect
 	golang.org/x/text v0.33.0 // indirect
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrInvalidLevel is returned when a level name is unknown.
+var ErrInvalidLevel = errors.New("invalid log level")
+
+// levelPayload is the JSON body of the level endpoint.
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+// errorPayload is the JSON body of level endpoint errors.
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+// ParseLevel returns the zapcore.Level matching a level name.
+// Names are case-insensitive and the trace (debug), warning (warn),
+// err (error) and critical (fatal) aliases are accepted.
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug", "trace":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "warn", "warning":
+		return zapcore.WarnLevel, nil
+	case "error", "err":
+		return zapcore.ErrorLevel, nil
+	case "dpanic":
+		return zapcore.DPanicLevel, nil
+	case "panic":
+		return zapcore.PanicLevel, nil
+	case "fatal", "critical":
+		return zapcore.FatalLevel, nil
+	}
+
+	return zapcore.InvalidLevel, fmt.Errorf("%w: %q", ErrInvalidLevel, level)
+}
+
+// SetLevel changes the logger level at runtime.
+// The level is shared with the parent and child loggers.
+func (l *DefaultLogger) SetLevel(level string) error {
+	zapLevel, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.level.SetLevel(zapLevel)
+	return nil
+}
+
+// Level returns the current logger level name.
+func (l *DefaultLogger) Level() string {
+	return l.level.Level().String()
+}
+
+// LevelHandler returns an http.Handler reporting the logger level on GET and
+// changing it on PUT, using a {"level":"debug"} JSON body in both cases.
+func (l *DefaultLogger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var payload levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				writeLevelError(w, http.StatusBadRequest, fmt.Errorf("decoding request body: %w", err))
+				return
+			}
+			if err := l.SetLevel(payload.Level); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+		default:
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodPut)
+			writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(levelPayload{Level: l.Level()})
+	})
+}
+
+// writeLevelError writes a JSON error response.
+func writeLevelError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorPayload{Error: err.Error()})
+}
@@ -0,0 +1,170 @@
+package log_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+func Test_SetLevel(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger := log.New()
+
+	is.Equal("info", logger.Level())
+	is.NoError(logger.SetLevel("debug"))
+	is.Equal("debug", logger.Level())
+
+	// Child loggers share the level.
+	child := logger.With()
+	is.NoError(child.SetLevel("warn"))
+	is.Equal("warn", logger.Level())
+}
+
+func Test_SetLevel_Invalid(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger := log.New(log.WithLevel("warn"))
+
+	err := logger.SetLevel("verbose")
+
+	is.ErrorIs(err, log.ErrInvalidLevel)
+	is.Equal("warn", logger.Level())
+}
+
+func Test_LevelHandler(t *testing.T) {
+	t.Parallel()
+	logger := log.New()
+	handler := logger.LevelHandler()
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		resp   string
+		level  zapcore.Level
+	}{
+		{
+			name:   "get",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			resp:   `{"level":"info"}`,
+			level:  zapcore.InfoLevel,
+		},
+		{
+			name:   "put",
+			method: http.MethodPut,
+			body:   `{"level":"debug"}`,
+			status: http.StatusOK,
+			resp:   `{"level":"debug"}`,
+			level:  zapcore.DebugLevel,
+		},
+		{
+			name:   "put invalid level",
+			method: http.MethodPut,
+			body:   `{"level":"verbose"}`,
+			status: http.StatusBadRequest,
+			resp:   `{"error":"invalid log level: \"verbose\""}`,
+			level:  zapcore.DebugLevel,
+		},
+		{
+			name:   "put invalid body",
+			method: http.MethodPut,
+			body:   `level=debug`,
+			status: http.StatusBadRequest,
+			level:  zapcore.DebugLevel,
+		},
+		{
+			name:   "post",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			level:  zapcore.DebugLevel,
+		},
+	}
+
+	// Cases share the logger and run in order.
+	for _, tc := range tests {
+		is := require.New(t)
+
+		req := httptest.NewRequest(tc.method, "/log/level", strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		is.Equal(tc.status, w.Code, tc.name)
+		if tc.resp != "" {
+			is.JSONEq(tc.resp, w.Body.String(), tc.name)
+		}
+		is.Equal(tc.level.String(), logger.Level(), tc.name)
+	}
+}
+
+func Test_ParseLevel(t *testing.T) {
+	t.Parallel()
+
+	levels := map[string]zapcore.Level{
+		"debug":    zapcore.DebugLevel,
+		"DEBUG":    zapcore.DebugLevel,
+		"trace":    zapcore.DebugLevel,
+		" Info ":   zapcore.InfoLevel,
+		"warn":     zapcore.WarnLevel,
+		"warning":  zapcore.WarnLevel,
+		"error":    zapcore.ErrorLevel,
+		"err":      zapcore.ErrorLevel,
+		"dpanic":   zapcore.DPanicLevel,
+		"panic":    zapcore.PanicLevel,
+		"fatal":    zapcore.FatalLevel,
+		"Critical": zapcore.FatalLevel,
+	}
+
+	for name, level := range levels {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+
+			l, err := log.ParseLevel(name)
+			is.NoError(err)
+			is.Equal(level, l)
+		})
+	}
+}
+
+func Test_ParseLevel_Invalid(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	for _, name := range []string{"", "verbose", "infos"} {
+		l, err := log.ParseLevel(name)
+		is.ErrorIs(err, log.ErrInvalidLevel)
+		is.Equal(zapcore.InvalidLevel, l)
+	}
+}
+
+func Test_WithStrictLevel(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	logger, err := log.Build(log.WithStrictLevel("WARNING"))
+	is.NoError(err)
+	is.Equal("warn", logger.Level())
+
+	logger, err = log.Build(log.WithStrictLevel("verbose"))
+	is.ErrorIs(err, log.ErrInvalidLevel)
+	is.Nil(logger)
+}
+
+func Test_New_InvalidConfiguration(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	logger := log.New(log.WithStrictLevel("verbose"))
+
+	is.NotNil(logger)
+	is.Equal("info", logger.Level())
+}
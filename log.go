@@ -2,13 +2,15 @@
 package log
 
 import (
+	"errors"
 	"os"
+	"time"
 
 	"github.com/getsentry/sentry-go"
-	"go.elastic.co/ecszap"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"go.pixelfactory.io/pkg/observability/log/redact"
 	zapsentry "go.pixelfactory.io/pkg/observability/log/sentry"
 )
 
@@ -24,60 +26,210 @@ type Logger interface {
 
 // DefaultLogger delegates all calls to the underlying zap.Logger.
 type DefaultLogger struct {
-	level  *zap.AtomicLevel
-	logger *zap.Logger
+	level            *zap.AtomicLevel
+	levels           *levelRegistry
+	logger           *zap.Logger
+	traceCorrelation bool
+	// sentry reports whether entries are sent to Sentry, with the hub of the context when set.
+	sentry  bool
+	closers *closers
+	async   *asyncWriter
+	// fatal is the behavior of Fatal, it panics like Panic when nil.
+	fatal FatalBehavior
+	// config is only set while options are applied.
+	config *config
+}
+
+// config holds the options used to build the logger core.
+type config struct {
+	output      zapcore.WriteSyncer
+	errorOutput zapcore.WriteSyncer
+	format      Format
+	sampling    *SamplingConfig
+	rateLimit   func(zapcore.Core) zapcore.Core
+	async       *asyncConfig
+	// asyncOverflow is set by WithAsyncOverflow, independently of WithAsync.
+	asyncOverflow OverflowPolicy
+	redactor      *redact.Redactor
+	// terminal reports whether the output is a terminal.
+	terminal      bool
+	sentryClient  *sentry.Client
+	sentryOptions []zapsentry.Option
+	// zapOptions are given before WithSentry, and sentryZapOptions after it.
+	zapOptions       []zap.Option
+	sentryZapOptions []zap.Option
+	// errs holds configuration errors reported by options.
+	errs []error
 }
 
 // Option type.
 type Option func(*DefaultLogger)
 
 // WithLevel logger level option.
+// Unknown levels fall back to info, see WithStrictLevel.
 func WithLevel(level string) Option {
 	return func(l *DefaultLogger) {
 		l.level.SetLevel(GetZapLogLevel(level))
 	}
 }
 
-// WithSentry enables sentry.
-func WithSentry(client *sentry.Client) Option {
+// WithStrictLevel logger level option.
+// Unknown levels make Build return an error.
+func WithStrictLevel(level string) Option {
 	return func(l *DefaultLogger) {
-		// Get Sentry zap Core that handle only Error level
-		sentryCore := zapsentry.NewCore(zapcore.ErrorLevel, client)
-		// NewTee creates a Core that duplicates log entries into two or more underlying Cores.
-		core := zapcore.NewTee(l.logger.Core(), sentryCore)
-		// Create new zap Logger
-		l.logger = newZapLogger(core)
+		zapLevel, err := ParseLevel(level)
+		if err != nil {
+			l.config.errs = append(l.config.errs, err)
+			return
+		}
+		l.level.SetLevel(zapLevel)
+	}
+}
+
+// WithSentry enables sentry, sending error entries as events.
+// Options configure the Sentry core, e.g. zapsentry.SetBreadcrumbLevel.
+func WithSentry(client *sentry.Client, opts ...zapsentry.Option) Option {
+	return func(l *DefaultLogger) {
+		l.config.sentryClient = client
+		l.config.sentryOptions = opts
 	}
 }
 
-// WithZapOption add fields.Service.
+// WithTraceCorrelation adds ECS trace.id and span.id of the active
+// OpenTelemetry span to entries logged through the *Context methods.
+func WithTraceCorrelation() Option {
+	return func(l *DefaultLogger) {
+		l.traceCorrelation = true
+	}
+}
+
+// WithZapOption adds zap options, applied once the logger core is built.
+// As options are applied in order, zap.WrapCore only wraps the Sentry core when given after WithSentry.
 func WithZapOption(opts ...zap.Option) Option {
 	return func(l *DefaultLogger) {
-		l.logger = l.logger.WithOptions(opts...)
+		l.config.addZapOptions(opts...)
 	}
 }
 
 // New returns a new logger with default values.
+// Configuration errors don't prevent the logger creation, they are logged
+// as a warning instead. Use Build to handle them.
 func New(opts ...Option) *DefaultLogger {
-	encoderConfig := ecszap.NewDefaultEncoderConfig()
+	l, err := build(opts...)
+	if err != nil {
+		l.Warn("Invalid logger configuration", zap.Error(err))
+	}
+
+	return l
+}
+
+// Build returns a new logger, or an error when an option is misconfigured.
+func Build(opts ...Option) (*DefaultLogger, error) {
+	l, err := build(opts...)
+	if err != nil {
+		// Release the outputs and goroutines already started.
+		_ = l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// build returns a new logger along with the configuration errors reported by options.
+func build(opts ...Option) (*DefaultLogger, error) {
 	atomicLevel := zap.NewAtomicLevelAt(zapcore.InfoLevel)
-	core := ecszap.NewCore(encoderConfig, os.Stdout, atomicLevel)
 
 	l := &DefaultLogger{
-		logger: newZapLogger(core),
-		level:  &atomicLevel,
+		level:   &atomicLevel,
+		levels:  newLevelRegistry(&atomicLevel),
+		closers: &closers{},
+		config: &config{
+			output:      zapcore.Lock(os.Stdout),
+			errorOutput: zapcore.Lock(os.Stderr),
+			format:      FormatECSJSON,
+			terminal:    isTerminal(os.Stdout),
+		},
 	}
 
 	for _, opt := range opts {
 		opt(l)
 	}
 
-	return l
+	cfg := l.config
+	l.config = nil
+
+	if f, ok := cfg.output.(*rotatingFile); ok {
+		f.setErrorOutput(cfg.errorOutput)
+	}
+
+	if cfg.async != nil {
+		l.async = newAsyncWriter(cfg.output, cfg.errorOutput, cfg.async, cfg.asyncOverflow)
+		l.closers.add(l.async.Close)
+	}
+
+	encoderCore, err := cfg.newEncoderCore(l.async)
+	if err != nil {
+		cfg.errs = append(cfg.errs, err)
+	}
+
+	if cfg.redactor != nil {
+		encoderCore = redact.NewRedactorCore(encoderCore, cfg.redactor)
+	}
+
+	if cfg.rateLimit != nil {
+		encoderCore = cfg.rateLimit(encoderCore)
+	}
+
+	var s *sampler
+	if cfg.sampling != nil {
+		s = &sampler{}
+		encoderCore = newSampledCore(encoderCore, cfg.sampling, s)
+	}
+
+	// Levels are enforced by levelCore, per logger name.
+	var core zapcore.Core = &levelCore{
+		Core:     encoderCore,
+		registry: l.levels,
+	}
+
+	// Zap options given before WithSentry only apply to the encoder core, e.g. zap.WrapCore.
+	l.logger = newZapLogger(core, zap.ErrorOutput(cfg.errorOutput)).WithOptions(cfg.zapOptions...)
+
+	if cfg.sentryClient != nil {
+		l.sentry = true
+		// Get Sentry zap Core that sends Error level entries as events, lower ones may be breadcrumbs
+		var sentryCore zapcore.Core = zapsentry.NewCore(zapcore.ErrorLevel, cfg.sentryClient, cfg.sentryOptions...)
+		if cfg.redactor != nil {
+			sentryCore = redact.NewRedactorCore(sentryCore, cfg.redactor)
+		}
+		// NewTee creates a Core that duplicates log entries into two or more underlying Cores.
+		l.logger = l.logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, sentryCore)
+		}))
+		// Zap options given after WithSentry apply to both cores.
+		l.logger = l.logger.WithOptions(cfg.sentryZapOptions...)
+	}
+
+	if s != nil {
+		s.start(l.logger.WithOptions(zap.WithCaller(false)), time.Duration(cfg.sampling.Tick), l.closers)
+	}
+
+	return l, errors.Join(cfg.errs...)
+}
+
+// addZapOptions adds zap options, applied before or after the Sentry core
+// depending on whether WithSentry was applied yet.
+func (c *config) addZapOptions(opts ...zap.Option) {
+	if c.sentryClient != nil {
+		c.sentryZapOptions = append(c.sentryZapOptions, opts...)
+		return
+	}
+	c.zapOptions = append(c.zapOptions, opts...)
 }
 
 // newZapLogger returns zap.Logger from zap Core.
-func newZapLogger(core zapcore.Core) *zap.Logger {
-	return zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+func newZapLogger(core zapcore.Core, opts ...zap.Option) *zap.Logger {
+	return zap.New(core, append([]zap.Option{zap.AddCaller(), zap.AddCallerSkip(1)}, opts...)...)
 }
 
 // Debug logs a debug msg with fields.
@@ -101,7 +253,12 @@ func (l *DefaultLogger) Error(msg string, fields ...zapcore.Field) {
 }
 
 // Fatal logs a fatal error msg with fields and panics. Apps will have to recover if ever needed.
+// Use WithFatalBehavior to exit instead.
 func (l *DefaultLogger) Fatal(msg string, fields ...zapcore.Field) {
+	if l.fatal != nil {
+		l.logger.Fatal(msg, fields...)
+		return
+	}
 	// Calls panic, as zap.Fatal calls os.Exit and isn't recoverable.
 	l.Panic(msg, fields...)
 }
@@ -111,6 +268,15 @@ func (l *DefaultLogger) Panic(msg string, fields ...zapcore.Field) {
 	l.logger.Panic(msg, fields...)
 }
 
+// Log logs a msg with fields at the given level.
+// Like Fatal, the fatal level panics instead of exiting, unless WithFatalBehavior is used.
+func (l *DefaultLogger) Log(level zapcore.Level, msg string, fields ...zapcore.Field) {
+	if level == zapcore.FatalLevel && l.fatal == nil {
+		level = zapcore.PanicLevel
+	}
+	l.logger.Log(level, msg, fields...)
+}
+
 // With creates a child logger, and optionally adds some context fields to that logger.
 func (l *DefaultLogger) With(fields ...zapcore.Field) *DefaultLogger {
 	clone := l.clone()
@@ -118,6 +284,19 @@ func (l *DefaultLogger) With(fields ...zapcore.Field) *DefaultLogger {
 	return clone
 }
 
+// WithOptions creates a child logger applying zap options, e.g. zap.WithCaller(false).
+func (l *DefaultLogger) WithOptions(opts ...zap.Option) *DefaultLogger {
+	clone := l.clone()
+	clone.logger = l.logger.WithOptions(opts...)
+	return clone
+}
+
+// RedirectStdLog redirects the output of the standard library log package to l at level,
+// and returns a function restoring the previous output, like zap.RedirectStdLogAt.
+func (l *DefaultLogger) RedirectStdLog(level zapcore.Level) (func(), error) {
+	return zap.RedirectStdLogAt(l.logger.WithOptions(zap.AddCallerSkip(-1)), level)
+}
+
 // Sync call zap.Logger Sync() method.
 func (l *DefaultLogger) Sync() error {
 	return l.logger.Sync()
@@ -129,21 +308,11 @@ func (l *DefaultLogger) clone() *DefaultLogger {
 }
 
 // GetZapLogLevel returns zap.AtomicLevel from string.
+// Unknown levels fall back to zapcore.InfoLevel, use ParseLevel to detect them.
 func GetZapLogLevel(logLevel string) zapcore.Level {
-	level := zapcore.InfoLevel
-	switch logLevel {
-	case "info":
-		level = zapcore.InfoLevel
-	case "debug":
-		level = zapcore.DebugLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
-	case "fatal":
-		level = zapcore.FatalLevel
-	case "panic":
-		level = zapcore.PanicLevel
+	level, err := ParseLevel(logLevel)
+	if err != nil {
+		return zapcore.InfoLevel
 	}
 
 	return level
@@ -80,6 +80,32 @@ func FuzzLogLevel(f *testing.F) {
 	})
 }
 
+// FuzzParseLevel fuzzes the ParseLevel function with arbitrary level strings.
+func FuzzParseLevel(f *testing.F) {
+	// Add seed corpus with valid, aliased and invalid log levels
+	f.Add("debug")
+	f.Add("DEBUG")
+	f.Add("warning")
+	f.Add("trace")
+	f.Add("critical")
+	f.Add("dpanic")
+	f.Add("")
+	f.Add("invalid")
+
+	f.Fuzz(func(t *testing.T, level string) {
+		// Should not panic with any input
+		result, err := log.ParseLevel(level)
+
+		// Either a valid level or an error is returned
+		if err == nil && (result < zapcore.DebugLevel || result > zapcore.FatalLevel) {
+			t.Errorf("ParseLevel returned invalid level: %v", result)
+		}
+		if err != nil && result != zapcore.InvalidLevel {
+			t.Errorf("ParseLevel returned level %v along with error: %v", result, err)
+		}
+	})
+}
+
 // FuzzLoggerWithFields fuzzes the logger with arbitrary field keys and values.
 func FuzzLoggerWithFields(f *testing.F) {
 	// Add seed corpus
@@ -95,6 +95,47 @@ func Test_WithSentry(t *testing.T) {
 	is.Implements((*log.Logger)(nil), logger)
 }
 
+func Test_WithZapOption_SentryOrder(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		wrapFirst  bool
+		sentEvents int
+	}{
+		// zap.WrapCore replaces the encoder core only, Sentry events are still sent.
+		{name: "before WithSentry", wrapFirst: true, sentEvents: 1},
+		// zap.WrapCore replaces both cores.
+		{name: "after WithSentry", wrapFirst: false, sentEvents: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+			tr := &transport{}
+
+			client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.test/1", Transport: tr})
+			is.NoError(err)
+
+			obsOpts, logs := setupObserver(zap.NewAtomicLevelAt(zapcore.DebugLevel))
+			opts := []log.Option{log.WithSentry(client), log.WithZapOption(obsOpts)}
+			if testCase.wrapFirst {
+				opts = []log.Option{log.WithZapOption(obsOpts), log.WithSentry(client)}
+			}
+
+			logger, err := log.Build(opts...)
+			is.NoError(err)
+			logger.Error(message)
+
+			is.Equal(1, logs.Len())
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			is.Len(tr.events, testCase.sentEvents)
+		})
+	}
+}
+
 func Test_With(t *testing.T) {
 	t.Parallel()
 	is := require.New(t)
@@ -214,3 +255,19 @@ func Test_Sync(t *testing.T) {
 
 	is.NoError(err)
 }
+
+func Test_Log(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	logger.Log(zap.WarnLevel, message)
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(zap.WarnLevel, entry.Level)
+		is.Equal(message, entry.Message)
+	}
+}
@@ -0,0 +1,484 @@
+// Package logfmt provides a Zap encoder writing entries as logfmt, with nested objects flattened to dotted keys.
+package logfmt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Name is the name the encoder is registered with, see zap.RegisterEncoder.
+const Name = "logfmt"
+
+const hex = "0123456789abcdef"
+
+//nolint:gochecknoglobals // Buffer pool shared by every encoder, as in zapcore.
+var pool = buffer.NewPool()
+
+//nolint:gochecknoinits // Registers the encoder so that zap.Config users can select it by name.
+func init() {
+	_ = zap.RegisterEncoder(Name, func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return NewEncoder(cfg), nil
+	})
+}
+
+// encoder is a zapcore.Encoder writing key=value pairs.
+// Keys of nested objects are prefixed by the object key and a dot,
+// and array elements are keyed by their index, e.g. tags.0=a tags.1=b.
+type encoder struct {
+	*zapcore.EncoderConfig
+
+	buf *buffer.Buffer
+	// prefix is prepended to keys, within objects and namespaces.
+	prefix string
+}
+
+// NewEncoder returns a logfmt encoder using the keys and the value encoders of cfg.
+func NewEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &encoder{
+		EncoderConfig: &cfg,
+		buf:           pool.Get(),
+	}
+}
+
+// AddArray adds the array elements keyed by their index.
+func (e *encoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return arr.MarshalLogArray(&arrayEncoder{encoder: e, key: e.prefix + key, indexed: true})
+}
+
+// AddObject adds the object fields with their keys prefixed by key.
+func (e *encoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return e.addObject(e.prefix+key, obj)
+}
+
+// AddBinary adds base64 encoded bytes.
+func (e *encoder) AddBinary(key string, value []byte) {
+	e.addKey(key)
+	e.appendString(base64.StdEncoding.EncodeToString(value))
+}
+
+// AddByteString adds UTF-8 encoded bytes.
+func (e *encoder) AddByteString(key string, value []byte) {
+	e.addKey(key)
+	e.appendString(string(value))
+}
+
+// AddBool adds a bool.
+func (e *encoder) AddBool(key string, value bool) {
+	e.addKey(key)
+	e.buf.AppendBool(value)
+}
+
+// AddComplex128 adds a complex128.
+func (e *encoder) AddComplex128(key string, value complex128) {
+	e.addKey(key)
+	e.appendComplex(value, 64)
+}
+
+// AddComplex64 adds a complex64.
+func (e *encoder) AddComplex64(key string, value complex64) {
+	e.addKey(key)
+	e.appendComplex(complex128(value), 32)
+}
+
+// AddDuration adds a duration, using the EncodeDuration encoder when set.
+func (e *encoder) AddDuration(key string, value time.Duration) {
+	e.valueEncoder(key).AppendDuration(value)
+}
+
+// AddFloat64 adds a float64.
+func (e *encoder) AddFloat64(key string, value float64) {
+	e.addKey(key)
+	e.appendFloat(value, 64)
+}
+
+// AddFloat32 adds a float32.
+func (e *encoder) AddFloat32(key string, value float32) {
+	e.addKey(key)
+	e.appendFloat(float64(value), 32)
+}
+
+// AddInt adds an int.
+func (e *encoder) AddInt(key string, value int) { e.AddInt64(key, int64(value)) }
+
+// AddInt64 adds an int64.
+func (e *encoder) AddInt64(key string, value int64) {
+	e.addKey(key)
+	e.buf.AppendInt(value)
+}
+
+// AddInt32 adds an int32.
+func (e *encoder) AddInt32(key string, value int32) { e.AddInt64(key, int64(value)) }
+
+// AddInt16 adds an int16.
+func (e *encoder) AddInt16(key string, value int16) { e.AddInt64(key, int64(value)) }
+
+// AddInt8 adds an int8.
+func (e *encoder) AddInt8(key string, value int8) { e.AddInt64(key, int64(value)) }
+
+// AddString adds a string, quoted when needed.
+func (e *encoder) AddString(key, value string) {
+	e.addKey(key)
+	e.appendString(value)
+}
+
+// AddTime adds a time, using the EncodeTime encoder when set.
+func (e *encoder) AddTime(key string, value time.Time) {
+	e.valueEncoder(key).AppendTime(value)
+}
+
+// AddUint adds an uint.
+func (e *encoder) AddUint(key string, value uint) { e.AddUint64(key, uint64(value)) }
+
+// AddUint64 adds an uint64.
+func (e *encoder) AddUint64(key string, value uint64) {
+	e.addKey(key)
+	e.buf.AppendUint(value)
+}
+
+// AddUint32 adds an uint32.
+func (e *encoder) AddUint32(key string, value uint32) { e.AddUint64(key, uint64(value)) }
+
+// AddUint16 adds an uint16.
+func (e *encoder) AddUint16(key string, value uint16) { e.AddUint64(key, uint64(value)) }
+
+// AddUint8 adds an uint8.
+func (e *encoder) AddUint8(key string, value uint8) { e.AddUint64(key, uint64(value)) }
+
+// AddUintptr adds an uintptr.
+func (e *encoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+
+// AddReflected adds a value encoded as JSON.
+func (e *encoder) AddReflected(key string, value interface{}) error {
+	e.addKey(key)
+	return e.appendReflected(value)
+}
+
+// OpenNamespace prefixes the keys of the fields added next.
+func (e *encoder) OpenNamespace(key string) {
+	e.prefix += key + "."
+}
+
+// Clone copies the encoder, along with the fields already added.
+func (e *encoder) Clone() zapcore.Encoder {
+	clone := e.clone()
+	_, _ = clone.buf.Write(e.buf.Bytes())
+	return clone
+}
+
+// EncodeEntry encodes the entry metadata, followed by the encoder fields and the entry fields.
+func (e *encoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := &encoder{EncoderConfig: e.EncoderConfig, buf: pool.Get()}
+
+	if final.TimeKey != "" && !ent.Time.IsZero() {
+		final.AddTime(final.TimeKey, ent.Time)
+	}
+	if final.LevelKey != "" && final.EncodeLevel != nil {
+		final.EncodeLevel(ent.Level, final.valueEncoder(final.LevelKey))
+	}
+	if final.NameKey != "" && ent.LoggerName != "" {
+		nameEncoder := final.EncodeName
+		if nameEncoder == nil {
+			nameEncoder = zapcore.FullNameEncoder
+		}
+		nameEncoder(ent.LoggerName, final.valueEncoder(final.NameKey))
+	}
+	if ent.Caller.Defined {
+		if final.CallerKey != "" && final.EncodeCaller != nil {
+			final.EncodeCaller(ent.Caller, final.valueEncoder(final.CallerKey))
+		}
+		if final.FunctionKey != "" {
+			final.AddString(final.FunctionKey, ent.Caller.Function)
+		}
+	}
+	if final.MessageKey != "" {
+		final.AddString(final.MessageKey, ent.Message)
+	}
+
+	if e.buf.Len() > 0 {
+		final.addSeparator()
+		_, _ = final.buf.Write(e.buf.Bytes())
+	}
+
+	final.prefix = e.prefix
+	for i := range fields {
+		fields[i].AddTo(final)
+	}
+	final.prefix = ""
+
+	if final.StacktraceKey != "" && ent.Stack != "" {
+		final.AddString(final.StacktraceKey, ent.Stack)
+	}
+
+	lineEnding := final.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	final.buf.AppendString(lineEnding)
+
+	return final.buf, nil
+}
+
+func (e *encoder) clone() *encoder {
+	return &encoder{
+		EncoderConfig: e.EncoderConfig,
+		buf:           pool.Get(),
+		prefix:        e.prefix,
+	}
+}
+
+// valueEncoder returns an encoder adding the values appended to it with key.
+func (e *encoder) valueEncoder(key string) *arrayEncoder {
+	return &arrayEncoder{encoder: e, key: e.prefix + key}
+}
+
+func (e *encoder) addObject(key string, obj zapcore.ObjectMarshaler) error {
+	prefix := e.prefix
+	e.prefix = key + "."
+	err := obj.MarshalLogObject(e)
+	e.prefix = prefix
+	return err
+}
+
+// addKey writes the separator and the prefixed key.
+func (e *encoder) addKey(key string) {
+	e.addFullKey(e.prefix + key)
+}
+
+func (e *encoder) addFullKey(key string) {
+	e.addSeparator()
+	if key == "" {
+		key = "_"
+	}
+	// Keys can't be quoted, invalid characters are replaced.
+	e.buf.AppendString(strings.Map(func(r rune) rune {
+		if isSpecial(r) {
+			return '_'
+		}
+		return r
+	}, key))
+	e.buf.AppendByte('=')
+}
+
+func (e *encoder) addSeparator() {
+	if e.buf.Len() > 0 {
+		e.buf.AppendByte(' ')
+	}
+}
+
+func (e *encoder) appendComplex(value complex128, bitSize int) {
+	e.appendString(strconv.FormatComplex(value, 'g', -1, bitSize))
+}
+
+func (e *encoder) appendFloat(value float64, bitSize int) {
+	switch {
+	case math.IsNaN(value):
+		e.buf.AppendString("NaN")
+	case math.IsInf(value, 1):
+		e.buf.AppendString("+Inf")
+	case math.IsInf(value, -1):
+		e.buf.AppendString("-Inf")
+	default:
+		e.buf.AppendFloat(value, bitSize)
+	}
+}
+
+func (e *encoder) appendReflected(value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	e.appendString(string(b))
+	return nil
+}
+
+// appendString writes the value, quoted and escaped when it's empty or contains
+// spaces, equal signs, quotes, backslashes or control characters.
+func (e *encoder) appendString(value string) {
+	if !needsQuoting(value) {
+		e.buf.AppendString(value)
+		return
+	}
+
+	e.buf.AppendByte('"')
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		i += size
+
+		switch {
+		case r == '"' || r == '\\':
+			e.buf.AppendByte('\\')
+			e.buf.AppendByte(byte(r))
+		case r == '\n':
+			e.buf.AppendString(`\n`)
+		case r == '\r':
+			e.buf.AppendString(`\r`)
+		case r == '\t':
+			e.buf.AppendString(`\t`)
+		case r < ' ' || r == 0x7f:
+			e.buf.AppendString(`\u00`)
+			e.buf.AppendByte(hex[r>>4])
+			e.buf.AppendByte(hex[r&0xf])
+		case r == utf8.RuneError && size == 1:
+			// Invalid UTF-8 is replaced.
+			e.buf.AppendString("\ufffd")
+		default:
+			e.buf.AppendString(value[i-size : i])
+		}
+	}
+	e.buf.AppendByte('"')
+}
+
+func needsQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	return strings.IndexFunc(value, isSpecial) >= 0
+}
+
+// isSpecial reports whether r is a space, an equal sign, a quote, a backslash,
+// a control character or an invalid UTF-8 rune.
+func isSpecial(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f || r == utf8.RuneError
+}
+
+// arrayEncoder adds the appended values with its key.
+// Indexed array encoders suffix the key with the element index.
+type arrayEncoder struct {
+	*encoder
+
+	key     string
+	indexed bool
+	index   int
+}
+
+// AppendArray appends a nested array.
+func (a *arrayEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
+	return arr.MarshalLogArray(&arrayEncoder{encoder: a.encoder, key: a.nextKey(), indexed: true})
+}
+
+// AppendObject appends an object, with its keys prefixed by the element key.
+func (a *arrayEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
+	return a.addObject(a.nextKey(), obj)
+}
+
+// AppendBool appends a bool.
+func (a *arrayEncoder) AppendBool(value bool) {
+	a.addFullKey(a.nextKey())
+	a.buf.AppendBool(value)
+}
+
+// AppendByteString appends UTF-8 encoded bytes.
+func (a *arrayEncoder) AppendByteString(value []byte) { a.AppendString(string(value)) }
+
+// AppendComplex128 appends a complex128.
+func (a *arrayEncoder) AppendComplex128(value complex128) {
+	a.addFullKey(a.nextKey())
+	a.appendComplex(value, 64)
+}
+
+// AppendComplex64 appends a complex64.
+func (a *arrayEncoder) AppendComplex64(value complex64) {
+	a.addFullKey(a.nextKey())
+	a.appendComplex(complex128(value), 32)
+}
+
+// AppendDuration appends a duration, using the EncodeDuration encoder when set.
+func (a *arrayEncoder) AppendDuration(value time.Duration) {
+	if a.EncodeDuration == nil {
+		a.AppendInt64(int64(value))
+		return
+	}
+	a.EncodeDuration(value, a)
+}
+
+// AppendFloat64 appends a float64.
+func (a *arrayEncoder) AppendFloat64(value float64) {
+	a.addFullKey(a.nextKey())
+	a.appendFloat(value, 64)
+}
+
+// AppendFloat32 appends a float32.
+func (a *arrayEncoder) AppendFloat32(value float32) {
+	a.addFullKey(a.nextKey())
+	a.appendFloat(float64(value), 32)
+}
+
+// AppendInt appends an int.
+func (a *arrayEncoder) AppendInt(value int) { a.AppendInt64(int64(value)) }
+
+// AppendInt64 appends an int64.
+func (a *arrayEncoder) AppendInt64(value int64) {
+	a.addFullKey(a.nextKey())
+	a.buf.AppendInt(value)
+}
+
+// AppendInt32 appends an int32.
+func (a *arrayEncoder) AppendInt32(value int32) { a.AppendInt64(int64(value)) }
+
+// AppendInt16 appends an int16.
+func (a *arrayEncoder) AppendInt16(value int16) { a.AppendInt64(int64(value)) }
+
+// AppendInt8 appends an int8.
+func (a *arrayEncoder) AppendInt8(value int8) { a.AppendInt64(int64(value)) }
+
+// AppendReflected appends a value encoded as JSON.
+func (a *arrayEncoder) AppendReflected(value interface{}) error {
+	a.addFullKey(a.nextKey())
+	return a.appendReflected(value)
+}
+
+// AppendString appends a string, quoted when needed.
+func (a *arrayEncoder) AppendString(value string) {
+	a.addFullKey(a.nextKey())
+	a.appendString(value)
+}
+
+// AppendTime appends a time, using the EncodeTime encoder when set.
+func (a *arrayEncoder) AppendTime(value time.Time) {
+	if a.EncodeTime == nil {
+		a.AppendInt64(value.UnixNano())
+		return
+	}
+	a.EncodeTime(value, a)
+}
+
+// AppendUint appends an uint.
+func (a *arrayEncoder) AppendUint(value uint) { a.AppendUint64(uint64(value)) }
+
+// AppendUint64 appends an uint64.
+func (a *arrayEncoder) AppendUint64(value uint64) {
+	a.addFullKey(a.nextKey())
+	a.buf.AppendUint(value)
+}
+
+// AppendUint32 appends an uint32.
+func (a *arrayEncoder) AppendUint32(value uint32) { a.AppendUint64(uint64(value)) }
+
+// AppendUint16 appends an uint16.
+func (a *arrayEncoder) AppendUint16(value uint16) { a.AppendUint64(uint64(value)) }
+
+// AppendUint8 appends an uint8.
+func (a *arrayEncoder) AppendUint8(value uint8) { a.AppendUint64(uint64(value)) }
+
+// AppendUintptr appends an uintptr.
+func (a *arrayEncoder) AppendUintptr(value uintptr) { a.AppendUint64(uint64(value)) }
+
+// nextKey returns the key of the next element.
+func (a *arrayEncoder) nextKey() string {
+	if !a.indexed {
+		return a.key
+	}
+
+	key := a.key + "." + strconv.Itoa(a.index)
+	a.index++
+	return key
+}
@@ -0,0 +1,106 @@
+package logfmt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/fields"
+	"go.pixelfactory.io/pkg/observability/log/logfmt"
+)
+
+func encode(t *testing.T, enc zapcore.Encoder, entry zapcore.Entry, fs ...zapcore.Field) string {
+	t.Helper()
+
+	buf, err := enc.EncodeEntry(entry, fs)
+	require.NoError(t, err)
+	defer buf.Free()
+	return buf.String()
+}
+
+func Test_Encoder(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	enc := logfmt.NewEncoder(zapcore.EncoderConfig{
+		TimeKey:        "ts",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		MessageKey:     "msg",
+		EncodeTime:     zapcore.RFC3339TimeEncoder,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	})
+	entry := zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		LoggerName: "db",
+		Message:    "query executed",
+	}
+
+	is.Equal(
+		`ts=2024-01-02T15:04:05Z level=info logger=db msg="query executed" `+
+			`service.name=myapp service.version=v1.0.0 `+
+			`tags.0=a tags.1="b c" data="aGk=" duration=1.5s ok=true count=3 `+
+			`quote="say \"hi\"\n" empty="" bad_key=1`+"\n",
+		encode(t, enc, entry,
+			fields.Service("myapp", "v1.0.0"),
+			zap.Strings("tags", []string{"a", "b c"}),
+			zap.Binary("data", []byte("hi")),
+			zap.Duration("duration", 1500*time.Millisecond),
+			zap.Bool("ok", true),
+			zap.Int("count", 3),
+			zap.String("quote", "say \"hi\"\n"),
+			zap.String("empty", ""),
+			zap.Int("bad key", 1),
+		),
+	)
+}
+
+func Test_Encoder_Clone(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	enc := logfmt.NewEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	child := enc.Clone()
+	zap.String("http.request.id", "abc").AddTo(child)
+	zap.Namespace("http").AddTo(child)
+
+	is.Equal(
+		"msg=served http.request.id=abc http.request.method=GET http.response.status_code=200\n",
+		encode(t, child, zapcore.Entry{Message: "served"},
+			fields.Object("request", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				enc.AddString("method", "GET")
+				return nil
+			})),
+			zap.Dict("response", zap.Int("status_code", 200)),
+		),
+	)
+	is.Equal("msg=served\n", encode(t, enc, zapcore.Entry{Message: "served"}))
+}
+
+func Test_Encoder_Error(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	enc := logfmt.NewEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	is.Equal(
+		"msg=failed error=\"connection refused\"\n",
+		encode(t, enc, zapcore.Entry{Message: "failed"}, zap.Error(errors.New("connection refused"))),
+	)
+}
+
+func Test_RegisterEncoder(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	cfg := zap.NewProductionConfig()
+	cfg.Encoding = logfmt.Name
+	cfg.OutputPaths = []string{"stdout"}
+	_, err := cfg.Build()
+	is.NoError(err)
+}
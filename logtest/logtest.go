@@ -0,0 +1,296 @@
+// Package logtest provides a logger recording its ECS JSON output and Sentry events in memory, for tests.
+package logtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+// sentryDSN is the DSN of the in-memory Sentry client, events are never sent.
+const sentryDSN = "https://public@sentry.test/1"
+
+// Entry is a logged entry, decoded from its ECS JSON encoding.
+type Entry struct {
+	Level      zapcore.Level
+	Message    string
+	LoggerName string
+	// Fields is the decoded JSON document, including the ECS keys such as @timestamp and log.level.
+	Fields map[string]interface{}
+}
+
+// Field returns the value at the dotted path, e.g. "http.request.method", and whether it's present.
+// Numbers are float64, objects are map[string]interface{} and arrays are []interface{}, as decoded by encoding/json.
+func (e Entry) Field(path string) (interface{}, bool) {
+	return lookup(e.Fields, path)
+}
+
+// Entries is a list of logged entries.
+type Entries []Entry
+
+// FilterLevel returns the entries logged at level.
+func (e Entries) FilterLevel(level zapcore.Level) Entries {
+	return e.filter(func(entry Entry) bool {
+		return entry.Level == level
+	})
+}
+
+// FilterMessage returns the entries logged with msg.
+func (e Entries) FilterMessage(msg string) Entries {
+	return e.filter(func(entry Entry) bool {
+		return entry.Message == msg
+	})
+}
+
+// FilterField returns the entries with value at the dotted path, e.g. FilterField("http.request.method", "GET").
+// Values are compared once encoded in JSON, so that FilterField("http.response.status_code", 200) matches.
+func (e Entries) FilterField(path string, value interface{}) Entries {
+	want, err := normalize(value)
+	if err != nil {
+		return nil
+	}
+
+	return e.filter(func(entry Entry) bool {
+		got, ok := entry.Field(path)
+		return ok && reflect.DeepEqual(got, want)
+	})
+}
+
+// Messages returns the entries messages.
+func (e Entries) Messages() []string {
+	msgs := make([]string, 0, len(e))
+	for _, entry := range e {
+		msgs = append(msgs, entry.Message)
+	}
+	return msgs
+}
+
+func (e Entries) filter(keep func(Entry) bool) Entries {
+	var filtered Entries
+	for _, entry := range e {
+		if keep(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// Logs records the output and Sentry events of a logger returned by New.
+type Logs struct {
+	tb        testing.TB
+	output    *buffer
+	transport *transport
+}
+
+// New returns a logger at the debug level, writing ECS JSON entries to the returned Logs,
+// and sending error entries to an in-memory Sentry client.
+// Options are applied after the logtest ones, so an output option stops the recording.
+// The logger is closed at the end of the test.
+func New(tb testing.TB, opts ...log.Option) (*log.DefaultLogger, *Logs) {
+	tb.Helper()
+
+	logs := &Logs{tb: tb, output: &buffer{}, transport: &transport{}}
+
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: sentryDSN, Transport: logs.transport})
+	if err != nil {
+		tb.Fatalf("logtest: create Sentry client: %v", err)
+	}
+
+	logger, err := log.Build(append([]log.Option{
+		log.WithLevel("debug"),
+		log.WithFormat(string(log.FormatECSJSON)),
+		log.WithOutput(logs.output),
+		log.WithSentry(client),
+	}, opts...)...)
+	if err != nil {
+		tb.Fatalf("logtest: build logger: %v", err)
+	}
+	tb.Cleanup(func() { _ = logger.Close() })
+
+	return logger, logs
+}
+
+// Entries returns the logged entries, decoded from the logger output. The test fails on invalid JSON.
+func (l *Logs) Entries() Entries {
+	l.tb.Helper()
+
+	var entries Entries
+	for _, line := range strings.Split(strings.TrimSpace(l.output.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		entry, err := decode(line)
+		if err != nil {
+			l.tb.Fatalf("logtest: decode entry %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Len returns the number of logged entries.
+func (l *Logs) Len() int {
+	l.tb.Helper()
+	return len(l.Entries())
+}
+
+// FilterLevel returns the entries logged at level.
+func (l *Logs) FilterLevel(level zapcore.Level) Entries {
+	l.tb.Helper()
+	return l.Entries().FilterLevel(level)
+}
+
+// FilterMessage returns the entries logged with msg.
+func (l *Logs) FilterMessage(msg string) Entries {
+	l.tb.Helper()
+	return l.Entries().FilterMessage(msg)
+}
+
+// FilterField returns the entries with value at the dotted path, see Entries.FilterField.
+func (l *Logs) FilterField(path string, value interface{}) Entries {
+	l.tb.Helper()
+	return l.Entries().FilterField(path, value)
+}
+
+// RequireLogged returns the first entry logged with msg, and fails the test when there's none.
+func (l *Logs) RequireLogged(tb testing.TB, msg string) Entry {
+	tb.Helper()
+
+	entries := l.Entries()
+	if matches := entries.FilterMessage(msg); len(matches) > 0 {
+		return matches[0]
+	}
+
+	tb.Fatalf("logtest: %q not logged, logged messages: %q", msg, entries.Messages())
+	return Entry{}
+}
+
+// Events returns the events sent to Sentry.
+func (l *Logs) Events() []*sentry.Event {
+	return l.transport.Events()
+}
+
+// Reset discards the recorded entries and events.
+func (l *Logs) Reset() {
+	l.output.Reset()
+	l.transport.Reset()
+}
+
+// decode decodes an ECS JSON entry.
+func decode(line string) (Entry, error) {
+	entry := Entry{}
+
+	if err := json.Unmarshal([]byte(line), &entry.Fields); err != nil {
+		return entry, err
+	}
+
+	if level, ok := entry.Fields["log.level"].(string); ok {
+		if err := entry.Level.UnmarshalText([]byte(level)); err != nil {
+			return entry, err
+		}
+	}
+	entry.Message, _ = entry.Fields["message"].(string)
+	entry.LoggerName, _ = entry.Fields["log.logger"].(string)
+
+	return entry, nil
+}
+
+// lookup returns the value at the dotted path. Keys may contain dots themselves,
+// such as the "http.request" key of fields.HTTPRequest, so every split is tried.
+func lookup(fields map[string]interface{}, path string) (interface{}, bool) {
+	if value, ok := fields[path]; ok {
+		return value, true
+	}
+
+	for i := range len(path) {
+		if path[i] != '.' {
+			continue
+		}
+
+		if nested, ok := fields[path[:i]].(map[string]interface{}); ok {
+			if value, found := lookup(nested, path[i+1:]); found {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// normalize returns value as decoded by encoding/json once encoded.
+func normalize(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	err = json.Unmarshal(b, &normalized)
+	return normalized, err
+}
+
+// buffer is a concurrency safe bytes.Buffer.
+type buffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *buffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *buffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+// transport is a sentry.Transport recording events instead of sending them.
+type transport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *transport) Flush(time.Duration) bool { return true }
+
+func (t *transport) FlushWithContext(context.Context) bool { return true }
+
+func (t *transport) Configure(sentry.ClientOptions) {}
+
+func (t *transport) Close() {}
+
+func (t *transport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *transport) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+func (t *transport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = nil
+}
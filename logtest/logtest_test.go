@@ -0,0 +1,52 @@
+package logtest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/fields"
+	"go.pixelfactory.io/pkg/observability/log/logtest"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := logtest.New(t)
+
+	req := httptest.NewRequest(http.MethodGet, "http://test/foo", http.NoBody)
+	logger.Named("http").Debug("request", fields.HTTPRequest(req), zap.Int("attempt", 2))
+	logger.Info("done")
+	logger.Error("failed", zap.Error(errors.New("timeout")))
+
+	is.Equal(3, logs.Len())
+	is.Equal([]string{"request", "done", "failed"}, logs.Entries().Messages())
+	is.Len(logs.FilterLevel(zapcore.InfoLevel), 1)
+	is.Len(logs.FilterField("http.request.method", http.MethodGet), 1)
+	is.Len(logs.FilterField("attempt", 2), 1)
+	is.Empty(logs.FilterField("attempt", "2"))
+
+	entry := logs.RequireLogged(t, "request")
+	is.Equal(zapcore.DebugLevel, entry.Level)
+	is.Equal("http", entry.LoggerName)
+	is.Contains(entry.Fields, "ecs.version")
+
+	entry = logs.RequireLogged(t, "failed")
+	is.Equal(zapcore.ErrorLevel, entry.Level)
+	message, ok := entry.Field("error.message")
+	is.True(ok)
+	is.Equal("timeout", message)
+
+	events := logs.Events()
+	is.Len(events, 1)
+	is.Equal("failed", events[0].Message)
+
+	logs.Reset()
+	is.Zero(logs.Len())
+	is.Empty(logs.Events())
+}
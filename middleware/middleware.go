@@ -0,0 +1,244 @@
+// Package middleware provides net/http middlewares and transports emitting ECS logs.
+package middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+// DefaultMessage is the message of access log entries.
+const DefaultMessage = "HTTP request served"
+
+// DefaultTransportMessage is the message of outbound request log entries.
+const DefaultTransportMessage = "HTTP request sent"
+
+// DefaultTransportErrorMessage is the message of outbound request transport error log entries.
+const DefaultTransportErrorMessage = "HTTP request failed"
+
+// DefaultRequestIDHeader is the header holding the request ID added to the request-scoped logger.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// Option type.
+type Option func(*options)
+
+type options struct {
+	message         string
+	requestIDHeader string
+	levelFunc       func(statusCode int) zapcore.Level
+	excludedPaths   map[string]struct{}
+	maxBodyBytes    int
+}
+
+func newOptions(message string, opts []Option) *options {
+	o := &options{
+		message:         message,
+		requestIDHeader: DefaultRequestIDHeader,
+		levelFunc:       DefaultLevel,
+		excludedPaths:   make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithMessage sets the message of access and outbound request log entries.
+func WithMessage(msg string) Option {
+	return func(o *options) {
+		o.message = msg
+	}
+}
+
+// WithLevelFunc sets the function choosing the access log level from the response status code.
+func WithLevelFunc(fn func(statusCode int) zapcore.Level) Option {
+	return func(o *options) {
+		o.levelFunc = fn
+	}
+}
+
+// WithExcludedPaths disables logs for requests on the given URL paths, e.g. health checks.
+// The request-scoped logger is still stored in the request context.
+func WithExcludedPaths(paths ...string) Option {
+	return func(o *options) {
+		for _, path := range paths {
+			o.excludedPaths[path] = struct{}{}
+		}
+	}
+}
+
+// WithRequestIDHeader sets the header holding the request ID, added as http.request.id
+// to the request-scoped logger. An empty name disables it.
+func WithRequestIDHeader(name string) Option {
+	return func(o *options) {
+		o.requestIDHeader = name
+	}
+}
+
+// WithBodyCapture logs up to maxBytes of outbound request and response bodies
+// as http.request.body.content and http.response.body.content.
+// Bodies are captured as they are read, it only applies to Transport.
+func WithBodyCapture(maxBytes int) Option {
+	return func(o *options) {
+		o.maxBodyBytes = maxBytes
+	}
+}
+
+// DefaultLevel returns Error for 5xx, Warn for 4xx and Info for any other status code.
+func DefaultLevel(statusCode int) zapcore.Level {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return zapcore.ErrorLevel
+	case statusCode >= http.StatusBadRequest:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+// New returns a middleware emitting one ECS access log per request, along with
+// the fields, trace and Sentry hub of the request context.
+// A panicking handler is logged with the 500 status code when it didn't write a response, then the panic goes on.
+func New(logger *log.DefaultLogger, opts ...Option) func(http.Handler) http.Handler {
+	o := newOptions(DefaultMessage, opts)
+	// The access log caller would always be this middleware.
+	accessLogger := logger.WithOptions(zap.WithCaller(false))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// Store the request-scoped logger in the request context.
+			requestLogger, requestAccessLogger := logger, accessLogger
+			if o.requestIDHeader != "" {
+				if id := r.Header.Get(o.requestIDHeader); id != "" {
+					requestLogger = logger.With(fields.String("http.request.id", id))
+					requestAccessLogger = accessLogger.With(fields.String("http.request.id", id))
+				}
+			}
+			r = r.WithContext(log.WithContext(r.Context(), requestLogger))
+
+			if _, ok := o.excludedPaths[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			panicked := true
+			defer func() {
+				if panicked && !rw.wroteHeader {
+					rw.statusCode = http.StatusInternalServerError
+				}
+				logAccess(requestAccessLogger, o, r, rw, time.Since(start))
+			}()
+
+			next.ServeHTTP(rw, r)
+			panicked = false
+		})
+	}
+}
+
+// logAccess logs the access log entry of a served request.
+func logAccess(logger *log.DefaultLogger, o *options, r *http.Request, rw *responseWriter, duration time.Duration) {
+	logger.LogContext(r.Context(), o.levelFunc(rw.statusCode), o.message,
+		fields.HTTPRequest(r),
+		fields.Object("http.response", &fields.HTTPResponseField{
+			Response: &http.Response{
+				StatusCode:    rw.statusCode,
+				ContentLength: rw.bytes,
+			},
+			BodyBytes: rw.bytes,
+		}),
+		fields.URL(r.URL),
+		fields.UserAgent(r.UserAgent()),
+		source(r.RemoteAddr),
+		fields.EventDuration(duration),
+	)
+}
+
+// Handler wraps next with the access log middleware.
+func Handler(logger *log.DefaultLogger, next http.Handler, opts ...Option) http.Handler {
+	return New(logger, opts...)(next)
+}
+
+// source returns ECS source from a request remote address.
+func source(remoteAddr string) zapcore.Field {
+	host, port, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return fields.Source(remoteAddr, 0)
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return fields.Source(host, 0)
+	}
+	return fields.Source(host, p)
+}
+
+// responseWriter records the status code and the number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+
+	statusCode  int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader records the status code and sends the response header.
+func (w *responseWriter) WriteHeader(statusCode int) {
+	// Informational responses, except protocol switches, precede the final one.
+	informational := statusCode >= http.StatusContinue && statusCode < http.StatusOK &&
+		statusCode != http.StatusSwitchingProtocols
+	if !w.wroteHeader && !informational {
+		w.statusCode = statusCode
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the number of bytes written.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the underlying http.ResponseWriter does.
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying http.ResponseWriter does, e.g. for websockets.
+// A hijacked connection is recorded with the 101 Switching Protocols status code, unless one was written.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err == nil && !w.wroteHeader {
+		w.statusCode = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return conn, buf, err
+}
+
+// Unwrap returns the underlying http.ResponseWriter, used by http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
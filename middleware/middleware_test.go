@@ -0,0 +1,224 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go.pixelfactory.io/pkg/observability/log"
+	"go.pixelfactory.io/pkg/observability/log/middleware"
+)
+
+const body = "<html><body>Hello Test!</body></html>"
+
+func setupLogger() (*log.DefaultLogger, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.NewAtomicLevelAt(zapcore.DebugLevel))
+	opts := zap.WrapCore(func(_ zapcore.Core) zapcore.Core {
+		return core
+	})
+	return log.New(log.WithLevel("debug"), log.WithZapOption(opts)), logs
+}
+
+func handler(statusCode int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(statusCode)
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func Test_Handler(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	req := httptest.NewRequest(http.MethodGet, "/foo?q=1", http.NoBody)
+	req.Header.Set("User-Agent", "curl/7.64.1")
+	w := httptest.NewRecorder()
+	middleware.Handler(logger, handler(http.StatusOK)).ServeHTTP(w, req)
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(zap.InfoLevel, entry.Level)
+		is.Equal(middleware.DefaultMessage, entry.Message)
+
+		ctx := entry.ContextMap()
+		is.Equal(map[string]interface{}{
+			"status_code": http.StatusOK,
+			"bytes":       int64(len(body)),
+			"body": map[string]interface{}{
+				"bytes": int64(len(body)),
+			},
+		}, ctx["http.response"])
+		is.Equal(map[string]interface{}{"path": "/foo", "query": "q=1"}, ctx["url"])
+		is.Equal(map[string]interface{}{"ip": "192.0.2.1", "port": 1234}, ctx["source"])
+		is.Equal("GET", ctx["http.request"].(map[string]interface{})["method"])
+		is.Equal("curl/7.64.1", ctx["user_agent"].(map[string]interface{})["original"])
+		is.Contains(ctx["event"], "duration")
+	}
+}
+
+func Test_Handler_Panic(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	panicking := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("handler failed")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/foo", http.NoBody)
+	is.PanicsWithValue("handler failed", func() {
+		middleware.Handler(logger, panicking).ServeHTTP(httptest.NewRecorder(), req)
+	})
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(zap.ErrorLevel, entry.Level)
+		is.False(entry.Caller.Defined)
+		is.Equal(http.StatusInternalServerError,
+			entry.ContextMap()["http.response"].(map[string]interface{})["status_code"])
+	}
+}
+
+func Test_Handler_Hijack(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	upgrade := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+			return
+		}
+
+		conn, buf, err := hijacker.Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		_ = buf.Flush()
+	})
+	server := httptest.NewServer(middleware.Handler(logger, upgrade))
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, http.NoBody)
+	is.NoError(err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+
+	resp, err := http.DefaultClient.Do(req)
+	is.NoError(err)
+	_ = resp.Body.Close()
+	is.Equal(http.StatusSwitchingProtocols, resp.StatusCode)
+
+	is.Eventually(func() bool { return logs.Len() == 1 }, time.Second, 10*time.Millisecond)
+	is.Equal(http.StatusSwitchingProtocols,
+		logs.All()[0].ContextMap()["http.response"].(map[string]interface{})["status_code"])
+}
+
+func Test_Handler_ContextFields(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	req := httptest.NewRequest(http.MethodGet, "/foo", http.NoBody)
+	req = req.WithContext(log.ContextWithFields(req.Context(), zap.String("tenant.id", "1")))
+	w := httptest.NewRecorder()
+	middleware.Handler(logger, handler(http.StatusOK)).ServeHTTP(w, req)
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		is.Equal("1", logs.All()[0].ContextMap()["tenant.id"])
+	}
+}
+
+func Test_Handler_Levels(t *testing.T) {
+	t.Parallel()
+
+	levels := map[int]zapcore.Level{
+		http.StatusOK:                  zap.InfoLevel,
+		http.StatusMovedPermanently:    zap.InfoLevel,
+		http.StatusNotFound:            zap.WarnLevel,
+		http.StatusInternalServerError: zap.ErrorLevel,
+	}
+
+	for statusCode, level := range levels {
+		t.Run(http.StatusText(statusCode), func(t *testing.T) {
+			t.Parallel()
+			is := require.New(t)
+			logger, logs := setupLogger()
+
+			req := httptest.NewRequest(http.MethodGet, "http://test/foo", http.NoBody)
+			middleware.Handler(logger, handler(statusCode)).ServeHTTP(httptest.NewRecorder(), req)
+
+			is.Equal(1, logs.Len())
+			is.Equal(level, logs.All()[0].Level)
+		})
+	}
+}
+
+func Test_WithLevelFunc(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	mw := middleware.New(logger, middleware.WithLevelFunc(func(int) zapcore.Level {
+		return zapcore.DebugLevel
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://test/foo", http.NoBody)
+	mw(handler(http.StatusInternalServerError)).ServeHTTP(httptest.NewRecorder(), req)
+
+	is.Equal(1, logs.Len())
+	is.Equal(zap.DebugLevel, logs.All()[0].Level)
+}
+
+func Test_WithExcludedPaths(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	var requestLogger *log.DefaultLogger
+	next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		requestLogger = log.FromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://test/healthz", http.NoBody)
+	middleware.Handler(logger, next, middleware.WithExcludedPaths("/healthz")).ServeHTTP(httptest.NewRecorder(), req)
+
+	is.Equal(0, logs.Len())
+	is.Same(logger, requestLogger)
+}
+
+func Test_RequestLogger(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		log.FromContext(r.Context()).Info("in handler")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://test/foo", http.NoBody)
+	req.Header.Set(middleware.DefaultRequestIDHeader, "abc")
+	middleware.Handler(logger, next).ServeHTTP(httptest.NewRecorder(), req)
+
+	is.Equal(2, logs.Len())
+	for _, entry := range logs.All() {
+		is.Equal("abc", entry.ContextMap()["http.request.id"])
+	}
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+// Transport is an http.RoundTripper logging every outbound request with ECS
+// http.request, http.response, url, destination and event.duration fields,
+// along with the fields, trace and Sentry hub of the request context.
+type Transport struct {
+	base    http.RoundTripper
+	logger  *log.DefaultLogger
+	options *options
+}
+
+// NewTransport returns a Transport logging requests sent through base.
+// http.DefaultTransport is used when base is nil.
+func NewTransport(logger *log.DefaultLogger, base http.RoundTripper, opts ...Option) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		base:    base,
+		logger:  logger,
+		options: newOptions(DefaultTransportMessage, opts),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+// With WithBodyCapture, bodies are captured as they are read, and the entry is logged once
+// the response body is read to the end or closed, so that streamed responses aren't delayed.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := t.options.excludedPaths[req.URL.Path]; ok {
+		return t.base.RoundTrip(req)
+	}
+
+	requestField := &fields.HTTPRequestField{Request: req}
+	var requestBody *captureBody
+	if t.options.maxBodyBytes > 0 {
+		req, requestField.BodyContent, requestBody = captureRequestBody(req, t.options.maxBodyBytes)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start)
+
+	logFields := []zapcore.Field{
+		fields.Object("http.request", requestField),
+		fields.URL(req.URL),
+		destination(req.URL),
+		fields.EventDuration(duration),
+	}
+
+	if err != nil {
+		if requestBody != nil {
+			requestField.BodyContent = requestBody.content()
+		}
+		t.logger.ErrorContext(req.Context(), DefaultTransportErrorMessage, append(logFields, fields.Error(err))...)
+		return nil, err
+	}
+
+	responseField := &fields.HTTPResponseField{Response: resp}
+	logResponse := func() {
+		if requestBody != nil {
+			requestField.BodyContent = requestBody.content()
+		}
+		t.logger.LogContext(
+			req.Context(),
+			t.options.levelFunc(resp.StatusCode),
+			t.options.message,
+			append(logFields, fields.Object("http.response", responseField))...,
+		)
+	}
+
+	if t.options.maxBodyBytes <= 0 || resp.Body == nil || resp.Body == http.NoBody {
+		logResponse()
+		return resp, nil
+	}
+
+	responseBody := &captureBody{ReadCloser: resp.Body, maxBytes: t.options.maxBodyBytes}
+	responseBody.done = func() {
+		responseField.BodyContent = responseBody.content()
+		logResponse()
+	}
+	resp.Body = responseBody
+	return resp, nil
+}
+
+// destination returns ECS destination from a request URL.
+func destination(u *url.URL) zapcore.Field {
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		switch u.Scheme {
+		case "https":
+			port = 443
+		case "http":
+			port = 80
+		}
+	}
+	return fields.Destination(u.Hostname(), port)
+}
+
+// captureRequestBody returns the request to send and up to maxBytes of its body, when a copy is available.
+// Otherwise, the request is shallow copied, and its body is captured as the transport reads it.
+func captureRequestBody(req *http.Request, maxBytes int) (*http.Request, string, *captureBody) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, "", nil
+	}
+
+	// Prefer a copy of the body when available, usually an in-memory reader.
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			content, _ := io.ReadAll(io.LimitReader(body, int64(maxBytes)))
+			_ = body.Close()
+			return req, string(content), nil
+		}
+	}
+
+	clone := new(http.Request)
+	*clone = *req
+
+	body := &captureBody{ReadCloser: req.Body, maxBytes: maxBytes}
+	clone.Body = body
+	return clone, "", body
+}
+
+// captureBody is an io.ReadCloser copying up to maxBytes of the body as it's read.
+// done is called once, when the body is read to the end or closed.
+type captureBody struct {
+	io.ReadCloser
+
+	maxBytes int
+	done     func()
+
+	mu   sync.Mutex
+	buf  []byte
+	once sync.Once
+}
+
+// Read reads from the body, and captures the bytes read.
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.mu.Lock()
+	if room := b.maxBytes - len(b.buf); room > 0 {
+		b.buf = append(b.buf, p[:min(n, room)]...)
+	}
+	b.mu.Unlock()
+
+	if errors.Is(err, io.EOF) {
+		b.finish()
+	}
+	return n, err
+}
+
+// Close closes the body.
+func (b *captureBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+// content returns the captured bytes. The request body may still be read by the transport.
+func (b *captureBody) content() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
+
+func (b *captureBody) finish() {
+	if b.done != nil {
+		b.once.Do(b.done)
+	}
+}
@@ -0,0 +1,206 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.pixelfactory.io/pkg/observability/log"
+	"go.pixelfactory.io/pkg/observability/log/middleware"
+)
+
+func Test_Transport(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	server := httptest.NewServer(handler(http.StatusNotFound))
+	defer server.Close()
+
+	client := &http.Client{Transport: middleware.NewTransport(logger, nil)}
+	reqCtx := log.ContextWithFields(context.Background(), zap.String("request.id", "1"))
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, server.URL+"/foo", http.NoBody)
+	is.NoError(err)
+
+	resp, err := client.Do(req)
+	is.NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(zap.WarnLevel, entry.Level)
+		is.Equal(middleware.DefaultTransportMessage, entry.Message)
+
+		ctx := entry.ContextMap()
+		is.Equal(http.StatusNotFound, ctx["http.response"].(map[string]interface{})["status_code"])
+		is.Equal(server.URL+"/foo", ctx["url"].(map[string]interface{})["full"])
+		is.Equal("127.0.0.1", ctx["destination"].(map[string]interface{})["ip"])
+		is.Contains(ctx["event"], "duration")
+		is.Equal("1", ctx["request.id"])
+	}
+}
+
+func Test_Transport_Error(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	server := httptest.NewServer(handler(http.StatusOK))
+	server.Close()
+
+	client := &http.Client{Transport: middleware.NewTransport(logger, nil)}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, http.NoBody)
+	is.NoError(err)
+
+	resp, err := client.Do(req)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	is.Error(err)
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(zap.ErrorLevel, entry.Level)
+		is.Equal(middleware.DefaultTransportErrorMessage, entry.Message)
+		is.Contains(entry.ContextMap(), "error")
+	}
+}
+
+func Test_Transport_WithBodyCapture(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+		_, _ = io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	transport := middleware.NewTransport(logger, nil, middleware.WithBodyCapture(5))
+	req, err := http.NewRequestWithContext(
+		context.Background(),
+		http.MethodPost,
+		server.URL,
+		io.NopCloser(strings.NewReader("request body")),
+	)
+	is.NoError(err)
+
+	resp, err := transport.RoundTrip(req)
+	is.NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	is.NoError(err)
+	is.Equal(body, string(respBody))
+	is.Equal("request body", received)
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		ctx := logs.All()[0].ContextMap()
+		is.Equal(
+			map[string]interface{}{"content": "reque"},
+			ctx["http.request"].(map[string]interface{})["body"],
+		)
+		is.Equal(
+			"<html",
+			ctx["http.response"].(map[string]interface{})["body"].(map[string]interface{})["content"],
+		)
+	}
+}
+
+func Test_Transport_WithBodyCapture_Streaming(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "data: 1\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = io.WriteString(w, "data: 2\n\n")
+	}))
+	defer server.Close()
+	defer close(release)
+
+	transport := middleware.NewTransport(logger, nil, middleware.WithBodyCapture(1024))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, http.NoBody)
+	is.NoError(err)
+
+	// The response is returned as soon as its headers are received.
+	resp, err := transport.RoundTrip(req)
+	is.NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	chunk := make([]byte, len("data: 1\n\n"))
+	_, err = io.ReadFull(resp.Body, chunk)
+	is.NoError(err)
+	is.Equal("data: 1\n\n", string(chunk))
+	is.Zero(logs.Len())
+
+	release <- struct{}{}
+	rest, err := io.ReadAll(resp.Body)
+	is.NoError(err)
+	is.Equal("data: 2\n\n", string(rest))
+
+	// The entry is logged once the body is read to the end.
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		ctx := logs.All()[0].ContextMap()
+		is.Equal(
+			"data: 1\n\ndata: 2\n\n",
+			ctx["http.response"].(map[string]interface{})["body"].(map[string]interface{})["content"],
+		)
+	}
+}
+
+func Test_Transport_WithBodyCapture_PipedRequest(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	// The request body is captured as it's sent, rather than read before.
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(pw, "request body")
+		_ = pw.Close()
+	}()
+
+	transport := middleware.NewTransport(logger, nil, middleware.WithBodyCapture(5))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, pr)
+	is.NoError(err)
+
+	resp, err := transport.RoundTrip(req)
+	is.NoError(err)
+	is.Zero(logs.Len())
+
+	// Closing the body without reading it logs the entry.
+	is.NoError(resp.Body.Close())
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		ctx := logs.All()[0].ContextMap()
+		is.Equal(map[string]interface{}{"content": "reque"}, ctx["http.request"].(map[string]interface{})["body"])
+	}
+}
@@ -0,0 +1,185 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultLevelName is the level spec name matching every logger without a more specific level.
+const DefaultLevelName = "*"
+
+// levelRegistry holds levels per logger name prefix, falling back to the logger default level.
+// Levels are published as an immutable snapshot, so that lookups don't lock.
+type levelRegistry struct {
+	// mu serializes the updates of the snapshot.
+	mu       sync.Mutex
+	fallback *zap.AtomicLevel
+	snapshot atomic.Pointer[levelSnapshot]
+}
+
+// levelSnapshot is an immutable set of levels per logger name prefix.
+type levelSnapshot struct {
+	levels map[string]zapcore.Level
+	// minLevel is the lowest level of levels, zapcore.InvalidLevel when there's none.
+	minLevel zapcore.Level
+}
+
+func newLevelRegistry(fallback *zap.AtomicLevel) *levelRegistry {
+	r := &levelRegistry{fallback: fallback}
+	r.snapshot.Store(&levelSnapshot{minLevel: zapcore.InvalidLevel})
+	return r
+}
+
+// set sets the level of the loggers named name or prefixed by name followed by a dot.
+func (r *levelRegistry) set(name string, level zapcore.Level) {
+	if name == DefaultLevelName {
+		r.fallback.SetLevel(level)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.snapshot.Load()
+	next := &levelSnapshot{
+		levels:   make(map[string]zapcore.Level, len(current.levels)+1),
+		minLevel: level,
+	}
+	for prefix, l := range current.levels {
+		next.levels[prefix] = l
+	}
+	next.levels[name] = level
+	for _, l := range next.levels {
+		next.minLevel = min(next.minLevel, l)
+	}
+	r.snapshot.Store(next)
+}
+
+// level returns the level of the longest name prefix matching the logger name.
+func (r *levelRegistry) level(name string) zapcore.Level {
+	levels := r.snapshot.Load().levels
+	if len(levels) == 0 {
+		return r.fallback.Level()
+	}
+
+	for prefix := name; prefix != ""; {
+		if level, ok := levels[prefix]; ok {
+			return level
+		}
+
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+
+	return r.fallback.Level()
+}
+
+// minLevel returns the lowest configured level.
+func (r *levelRegistry) minLevel() zapcore.Level {
+	return min(r.fallback.Level(), r.snapshot.Load().minLevel)
+}
+
+// levelCore filters entries using the level registered for their logger name.
+// The wrapped core must enable every level.
+type levelCore struct {
+	zapcore.Core
+
+	registry *levelRegistry
+}
+
+// Enabled reports whether at least one logger name enables the level.
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.registry.minLevel()
+}
+
+// Check adds the wrapped core when the entry logger name enables its level.
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level >= c.registry.level(entry.LoggerName) {
+		return c.Core.Check(entry, checked)
+	}
+	return checked
+}
+
+// With adds structured context to the wrapped core.
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{
+		Core:     c.Core.With(fields),
+		registry: c.registry,
+	}
+}
+
+// ParseLevelSpec parses a comma separated list of name=level pairs, e.g. "db=debug,http=warn,*=info".
+// A name matches the logger with that name and its named children ("db" matches "db" and "db.postgres").
+// A level without name, or with the "*" name, is the default level.
+func ParseLevelSpec(spec string) (map[string]zapcore.Level, error) {
+	levels := make(map[string]zapcore.Level)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, levelName, found := strings.Cut(part, "=")
+		if !found {
+			name, levelName = DefaultLevelName, part
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: missing logger name in %q", ErrInvalidLevel, part)
+		}
+
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			return nil, err
+		}
+		levels[name] = level
+	}
+
+	return levels, nil
+}
+
+// WithLevelSpec sets levels per logger name, see ParseLevelSpec.
+// An invalid spec makes Build return an error.
+func WithLevelSpec(spec string) Option {
+	return func(l *DefaultLogger) {
+		levels, err := ParseLevelSpec(spec)
+		if err != nil {
+			l.config.errs = append(l.config.errs, err)
+			return
+		}
+
+		for name, level := range levels {
+			l.levels.set(name, level)
+		}
+	}
+}
+
+// Named creates a named child logger. Names of nested child loggers are
+// joined with a dot, and are used to look up per name levels.
+func (l *DefaultLogger) Named(name string) *DefaultLogger {
+	clone := l.clone()
+	clone.logger = l.logger.Named(name)
+	return clone
+}
+
+// SetNamedLevel changes at runtime the level of the loggers named name and their children.
+// The "*" name changes the default level.
+func (l *DefaultLogger) SetNamedLevel(name, level string) error {
+	zapLevel, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.levels.set(name, zapLevel)
+	return nil
+}
@@ -0,0 +1,145 @@
+package log_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+// entryRecorder records the entries written by the logger core.
+type entryRecorder struct {
+	mu      sync.Mutex
+	entries []zapcore.Entry
+}
+
+func (r *entryRecorder) hook(entry zapcore.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *entryRecorder) messages() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := make([]string, 0, len(r.entries))
+	for _, entry := range r.entries {
+		messages = append(messages, entry.LoggerName+":"+entry.Message)
+	}
+	return messages
+}
+
+func Test_Named(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	logger.Named("db").Named("postgres").Info(message)
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		is.Equal("db.postgres", logs.All()[0].LoggerName)
+	}
+}
+
+func Test_WithLevelSpec(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	recorder := &entryRecorder{}
+	logger := log.New(
+		log.WithLevelSpec("db=debug,http=warn,*=info"),
+		log.WithZapOption(zap.Hooks(recorder.hook)),
+	)
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Named("db").Debug("debug")
+	logger.Named("db").Named("postgres").Debug("debug")
+	logger.Named("dbx").Debug("debug")
+	logger.Named("http").Info("info")
+	logger.Named("http").Warn("warn")
+
+	is.Equal([]string{
+		":info",
+		"db:debug",
+		"db.postgres:debug",
+		"http:warn",
+	}, recorder.messages())
+}
+
+func Test_SetNamedLevel(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	recorder := &entryRecorder{}
+	logger := log.New(log.WithZapOption(zap.Hooks(recorder.hook)))
+	db := logger.Named("db")
+
+	db.Debug("before")
+	is.NoError(logger.SetNamedLevel("db", "debug"))
+	db.Debug("after")
+	is.NoError(logger.SetNamedLevel("*", "error"))
+	logger.Warn("default")
+
+	is.ErrorIs(logger.SetNamedLevel("db", "verbose"), log.ErrInvalidLevel)
+	is.Equal([]string{"db:after"}, recorder.messages())
+	is.Equal("error", logger.Level())
+}
+
+func Test_SetNamedLevel_Concurrent(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	recorder := &entryRecorder{}
+	logger := log.New(log.WithLevel("error"), log.WithZapOption(zap.Hooks(recorder.hook)))
+	db := logger.Named("db")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			db.Debug("debug")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			_ = logger.SetNamedLevel("http", "debug")
+		}
+	}()
+	wg.Wait()
+	is.Empty(recorder.messages())
+
+	is.NoError(logger.SetNamedLevel("db.postgres", "debug"))
+	db.Debug("debug")
+	db.Named("postgres").Debug("debug")
+	is.Equal([]string{"db.postgres:debug"}, recorder.messages())
+}
+
+func Test_ParseLevelSpec(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	levels, err := log.ParseLevelSpec(" db=DEBUG, http = warn ,info")
+	is.NoError(err)
+	is.Equal(map[string]zapcore.Level{
+		"db":   zapcore.DebugLevel,
+		"http": zapcore.WarnLevel,
+		"*":    zapcore.InfoLevel,
+	}, levels)
+
+	_, err = log.ParseLevelSpec("db=verbose")
+	is.ErrorIs(err, log.ErrInvalidLevel)
+
+	_, err = log.ParseLevelSpec("=debug")
+	is.ErrorIs(err, log.ErrInvalidLevel)
+
+	_, err = log.Build(log.WithLevelSpec("db=verbose"))
+	is.ErrorIs(err, log.ErrInvalidLevel)
+}
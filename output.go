@@ -0,0 +1,90 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// closers holds the resources opened by the logger, closed once by Close.
+type closers struct {
+	once  sync.Once
+	funcs []func() error
+	err   error
+}
+
+func (c *closers) add(fn func() error) {
+	c.funcs = append(c.funcs, fn)
+}
+
+// close calls flush, then the close funcs in reverse order, as deferred calls.
+// Later calls return the first call error.
+func (c *closers) close(flush func() error) error {
+	c.once.Do(func() {
+		errs := make([]error, 0, len(c.funcs)+1)
+		errs = append(errs, flush())
+		for i := len(c.funcs) - 1; i >= 0; i-- {
+			errs = append(errs, c.funcs[i]())
+		}
+		c.err = errors.Join(errs...)
+	})
+	return c.err
+}
+
+// WithOutput writes log entries to w instead of os.Stdout.
+// Writes are serialized, w doesn't need to be safe for concurrent use.
+func WithOutput(w io.Writer) Option {
+	return func(l *DefaultLogger) {
+		l.config.output = zapcore.Lock(zapcore.AddSync(w))
+		l.config.terminal = isTerminal(w)
+	}
+}
+
+// WithOutputPaths writes log entries to every path: "stdout", "stderr", a file
+// path or a URL whose scheme is registered with zap.RegisterSink.
+// Opened files are closed by Close. A path that can't be opened makes Build return an error.
+func WithOutputPaths(paths ...string) Option {
+	return func(l *DefaultLogger) {
+		ws, closeSinks, err := zap.Open(paths...)
+		if err != nil {
+			l.config.errs = append(l.config.errs, err)
+			return
+		}
+
+		l.config.output = ws
+		l.config.terminal = len(paths) == 1 && isStdTerminal(paths[0])
+		l.closers.add(func() error {
+			closeSinks()
+			return nil
+		})
+	}
+}
+
+// isStdTerminal reports whether path is the stdout or stderr path, and is a terminal.
+func isStdTerminal(path string) bool {
+	switch path {
+	case "stdout":
+		return isTerminal(os.Stdout)
+	case "stderr":
+		return isTerminal(os.Stderr)
+	}
+	return false
+}
+
+// WithErrorOutput writes internal logger errors, such as encoding or write
+// failures, to w instead of os.Stderr.
+func WithErrorOutput(w io.Writer) Option {
+	return func(l *DefaultLogger) {
+		l.config.errorOutput = zapcore.Lock(zapcore.AddSync(w))
+	}
+}
+
+// Close flushes buffered log entries and releases the outputs opened by the logger.
+// Child loggers share their parent outputs, Close only needs to be called once.
+func (l *DefaultLogger) Close() error {
+	return l.closers.close(l.Sync)
+}
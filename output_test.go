@@ -0,0 +1,101 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func Test_WithOutput(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger := log.New(log.WithOutput(&buf))
+	logger.Info(message)
+
+	var entry map[string]interface{}
+	is.NoError(json.Unmarshal(buf.Bytes(), &entry))
+	is.Equal(message, entry["message"])
+	is.Equal("info", entry["log.level"])
+}
+
+func Test_WithOutputPaths(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	logger, err := log.Build(log.WithOutputPaths(path))
+	is.NoError(err)
+	logger.Info(message)
+	logger.With().Info(message)
+	is.NoError(logger.Close())
+	is.NoError(logger.Close())
+
+	content, err := os.ReadFile(path)
+	is.NoError(err)
+	is.Equal(2, bytes.Count(content, []byte(message)))
+
+	_, err = log.Build(log.WithOutputPaths(filepath.Join(t.TempDir(), "missing", "test.log")))
+	is.Error(err)
+}
+
+// closeSink is a zap.Sink recording whether it's closed.
+type closeSink struct {
+	bytes.Buffer
+
+	closed atomic.Bool
+}
+
+func (s *closeSink) Sync() error { return nil }
+
+func (s *closeSink) Close() error {
+	s.closed.Store(true)
+	return nil
+}
+
+func Test_Build_ErrorCloses(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	sink := &closeSink{}
+
+	// Schemes can only be registered once per process, e.g. with -count.
+	scheme := "closesink" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	is.NoError(zap.RegisterSink(scheme, func(*url.URL) (zap.Sink, error) {
+		return sink, nil
+	}))
+
+	logger, err := log.Build(log.WithOutputPaths(scheme+"://test"), log.WithStrictLevel("invalid"))
+	is.Error(err)
+	is.Nil(logger)
+	is.True(sink.closed.Load())
+}
+
+func Test_WithErrorOutput(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger := log.New(log.WithOutput(failingWriter{}), log.WithErrorOutput(&buf))
+	logger.Info(message)
+
+	is.Contains(buf.String(), "write failed")
+}
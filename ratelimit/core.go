@@ -0,0 +1,223 @@
+// Package ratelimit provides a Zap core wrapper rate limiting entries per key, with a token bucket per key.
+package ratelimit
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SuppressedCountKey is the key of the field counting the entries suppressed
+// since the previous entry with the same key.
+const SuppressedCountKey = "log.suppressed_count"
+
+// DefaultMaxKeys is the default number of keys tracked, see WithMaxKeys.
+const DefaultMaxKeys = 1000
+
+// KeyFunc returns the key entries are rate limited by.
+// Fields include the context fields added with With.
+type KeyFunc func(entry zapcore.Entry, fields []zapcore.Field) string
+
+// MessageKey rate limits entries by message.
+func MessageKey(entry zapcore.Entry, _ []zapcore.Field) string {
+	return entry.Message
+}
+
+// MessageErrorKey rate limits entries by message and type of the first error field.
+func MessageErrorKey(entry zapcore.Entry, fields []zapcore.Field) string {
+	for _, field := range fields {
+		if field.Type == zapcore.ErrorType {
+			return fmt.Sprintf("%s\x00%T", entry.Message, field.Interface)
+		}
+	}
+	return entry.Message
+}
+
+// FieldKey rate limits entries by the value of the field named key.
+// Entries without that field are rate limited by message.
+func FieldKey(key string) KeyFunc {
+	return func(entry zapcore.Entry, fields []zapcore.Field) string {
+		for _, field := range fields {
+			if field.Key != key {
+				continue
+			}
+
+			// Fields are encoded to support every field type.
+			enc := zapcore.NewMapObjectEncoder()
+			field.AddTo(enc)
+			return fmt.Sprintf("\x00%v", enc.Fields[key])
+		}
+		return entry.Message
+	}
+}
+
+// Option type.
+type Option func(*Core)
+
+// WithKeyFunc sets the function returning the key entries are rate limited by.
+// Entries are rate limited by message by default.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(core *Core) {
+		core.limiter.keyFunc = fn
+	}
+}
+
+// WithMaxKeys sets the number of keys tracked. The least recently used keys are evicted first.
+func WithMaxKeys(n int) Option {
+	return func(core *Core) {
+		core.limiter.maxKeys = n
+	}
+}
+
+// WithClock sets the clock used to refill token buckets.
+func WithClock(clock zapcore.Clock) Option {
+	return func(core *Core) {
+		core.limiter.clock = clock
+	}
+}
+
+// Core is a zapcore.Core rate limiting the entries written to the wrapped core.
+// Each key has a token bucket of burst tokens, refilled at rate tokens per second.
+type Core struct {
+	zapcore.Core
+
+	limiter *limiter
+	fields  []zapcore.Field
+}
+
+// NewCore returns a Core rate limiting entries written to core.
+func NewCore(core zapcore.Core, rate float64, burst int, options ...Option) *Core {
+	c := &Core{
+		Core: core,
+		limiter: &limiter{
+			rate:    rate,
+			burst:   float64(burst),
+			keyFunc: MessageKey,
+			maxKeys: DefaultMaxKeys,
+			clock:   zapcore.DefaultClock,
+			buckets: make(map[string]*list.Element),
+			lru:     list.New(),
+		},
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+// With adds structured context to the Core.
+// Child cores share the token buckets of their parent.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+
+	clone.fields = make([]zapcore.Field, len(c.fields)+len(fields))
+	copy(clone.fields, c.fields)
+	copy(clone.fields[len(c.fields):], fields)
+
+	return &clone
+}
+
+// Check adds the Core to the entry when the wrapped core enables its level.
+// Entries are rate limited when written, as the key may depend on their fields.
+func (c *Core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write writes the entry to the wrapped core, unless its key has no token left.
+// The number of entries suppressed since the previous write is added to the entry.
+// Entries go through the wrapped core Check, so that samplers and tees apply to them.
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	keyFields := fields
+	if len(c.fields) > 0 {
+		keyFields = make([]zapcore.Field, 0, len(c.fields)+len(fields))
+		keyFields = append(append(keyFields, c.fields...), fields...)
+	}
+
+	allowed, suppressed := c.limiter.allow(c.limiter.keyFunc(entry, keyFields))
+	if !allowed {
+		return nil
+	}
+
+	if suppressed > 0 {
+		fields = append(fields[:len(fields):len(fields)], zap.Uint64(SuppressedCountKey, suppressed))
+	}
+
+	if checked := c.Core.Check(entry, nil); checked != nil {
+		checked.Write(fields...)
+	}
+	return nil
+}
+
+// bucket is the token bucket of a key.
+type bucket struct {
+	key        string
+	tokens     float64
+	updatedAt  time.Time
+	suppressed uint64
+}
+
+// limiter holds the token buckets of the most recently used keys.
+type limiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	keyFunc KeyFunc
+	maxKeys int
+	clock   zapcore.Clock
+	buckets map[string]*list.Element
+	lru     *list.List
+}
+
+// allow takes a token from the key bucket. It returns whether a token was
+// available and, when it was, the number of suppressed entries since the last one.
+func (l *limiter) allow(key string) (bool, uint64) {
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key, now)
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.updatedAt).Seconds()*l.rate)
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		b.suppressed++
+		return false, 0
+	}
+
+	b.tokens--
+	suppressed := b.suppressed
+	b.suppressed = 0
+	return true, suppressed
+}
+
+// bucket returns the key bucket, created full when missing.
+func (l *limiter) bucket(key string, now time.Time) *bucket {
+	if elem, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(elem)
+		b, _ := elem.Value.(*bucket)
+		return b
+	}
+
+	if l.maxKeys > 0 && l.lru.Len() >= l.maxKeys {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		if b, ok := oldest.Value.(*bucket); ok {
+			delete(l.buckets, b.key)
+		}
+	}
+
+	b := &bucket{key: key, tokens: l.burst, updatedAt: now}
+	l.buckets[key] = l.lru.PushFront(b)
+	return b
+}
@@ -0,0 +1,111 @@
+package ratelimit_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go.pixelfactory.io/pkg/observability/log/ratelimit"
+)
+
+// clock is a manually advanced zapcore.Clock.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func setupLogger(opts ...ratelimit.Option) (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(ratelimit.NewCore(core, 1, 2, opts...)), logs
+}
+
+func Test_Core(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	c := &clock{now: time.Now()}
+	logger, logs := setupLogger(ratelimit.WithClock(c))
+
+	for range 5 {
+		logger.Info("retry")
+	}
+	logger.Info("other")
+	is.Equal(3, logs.Len())
+
+	c.now = c.now.Add(time.Second)
+	logger.With(zap.String("attempt", "last")).Info("retry")
+
+	entries := logs.TakeAll()
+	is.Len(entries, 4)
+	is.Equal(map[string]interface{}{
+		"attempt":                    "last",
+		ratelimit.SuppressedCountKey: uint64(3),
+	}, entries[3].ContextMap())
+}
+
+func Test_MessageErrorKey(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger(ratelimit.WithKeyFunc(ratelimit.MessageErrorKey))
+
+	for range 3 {
+		logger.Error("failed", zap.Error(errors.New("timeout")))
+		logger.Error("failed", zap.Error(&fs.PathError{Op: "open", Path: "/tmp", Err: os.ErrNotExist}))
+	}
+	is.Equal(4, logs.Len())
+}
+
+func Test_FieldKey(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger(ratelimit.WithKeyFunc(ratelimit.FieldKey("user.id")))
+
+	for i := range 6 {
+		logger.Info("request", zap.Int("user.id", i%2), zap.Int("attempt", i))
+	}
+	is.Equal(4, logs.Len())
+}
+
+func Test_WithMaxKeys(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger(ratelimit.WithMaxKeys(1))
+
+	// Evicted keys get a full bucket again.
+	for range 3 {
+		logger.Info("a")
+		logger.Info("b")
+	}
+	is.Equal(6, logs.Len())
+}
+
+func Test_Core_WrappedCore(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	warnCore, warnLogs := observer.New(zapcore.WarnLevel)
+	debugCore, debugLogs := observer.New(zapcore.DebugLevel)
+	sampled := zapcore.NewSamplerWithOptions(debugCore, time.Minute, 1, 0)
+
+	logger := zap.New(ratelimit.NewCore(zapcore.NewTee(warnCore, sampled), 1, 10))
+	for range 3 {
+		logger.Debug("retry")
+	}
+	logger.Warn("failed")
+
+	is.Equal(1, warnLogs.Len())
+	is.Equal(zapcore.WarnLevel, warnLogs.All()[0].Level)
+	is.Equal(2, debugLogs.Len())
+}
@@ -0,0 +1,48 @@
+package redact
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// Core is a zapcore.Core redacting the messages and fields of the entries written to the wrapped core.
+type Core struct {
+	zapcore.Core
+
+	r *Redactor
+}
+
+// NewCore returns a Core redacting entries with rules before writing them to core.
+func NewCore(core zapcore.Core, rules ...Rule) *Core {
+	return NewRedactorCore(core, New(rules...))
+}
+
+// NewRedactorCore returns a Core redacting entries with r before writing them to core.
+func NewRedactorCore(core zapcore.Core, r *Redactor) *Core {
+	return &Core{Core: core, r: r}
+}
+
+// With adds structured context to the core, redacted.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	return &Core{
+		Core: c.Core.With(c.r.Fields(fields)),
+		r:    c.r,
+	}
+}
+
+// Check adds the core to the entry when its level is enabled.
+func (c *Core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write redacts the entry message and fields, and writes them to the wrapped core.
+// Entries go through the wrapped core Check, so that samplers and tees apply to them.
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.r.String(entry.Message)
+	if checked := c.Core.Check(entry, nil); checked != nil {
+		checked.Write(c.r.Fields(fields)...)
+	}
+	return nil
+}
@@ -0,0 +1,458 @@
+package redact
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Fields returns a copy of fields with their values redacted.
+// Nested objects and arrays are redacted when marshaled.
+func (r *Redactor) Fields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, 0, len(fields))
+
+	prefix := ""
+	for _, field := range fields {
+		if f, ok := r.field(prefix, field); ok {
+			redacted = append(redacted, f)
+		}
+
+		if field.Type == zapcore.NamespaceType {
+			prefix += field.Key + "."
+		}
+	}
+	return redacted
+}
+
+// field returns the redacted field, or false when it's dropped.
+//
+//nolint:exhaustive // Other field types are scalars, only redacted by key rules.
+func (r *Redactor) field(prefix string, field zapcore.Field) (zapcore.Field, bool) {
+	switch field.Type {
+	case zapcore.SkipType, zapcore.NamespaceType:
+		return field, true
+	case zapcore.InlineMarshalerType:
+		if obj, ok := field.Interface.(zapcore.ObjectMarshaler); ok {
+			field.Interface = &object{ObjectMarshaler: obj, r: r, prefix: prefix}
+		}
+		return field, true
+	}
+
+	path := prefix + field.Key
+	if strategy := r.keyStrategy(path); strategy != nil {
+		return redactedField(field, strategy)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = r.String(field.String)
+	case zapcore.ByteStringType:
+		if b, ok := field.Interface.([]byte); ok {
+			return zap.String(field.Key, r.String(string(b))), true
+		}
+	case zapcore.StringerType:
+		if s, ok := field.Interface.(fmt.Stringer); ok {
+			return zap.String(field.Key, r.String(s.String())), true
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			field.Interface = r.error(err)
+		}
+	case zapcore.ObjectMarshalerType:
+		if obj, ok := field.Interface.(zapcore.ObjectMarshaler); ok {
+			field.Interface = &object{ObjectMarshaler: obj, r: r, prefix: path + "."}
+		}
+	case zapcore.ArrayMarshalerType:
+		if arr, ok := field.Interface.(zapcore.ArrayMarshaler); ok {
+			field.Interface = &array{ArrayMarshaler: arr, r: r, prefix: path + "."}
+		}
+	case zapcore.ReflectType:
+		if obj := r.reflected(path, field.Interface); obj != nil {
+			return zap.Object(field.Key, obj), true
+		}
+		if s, ok := field.Interface.(string); ok {
+			field.Interface = r.String(s)
+		}
+	}
+	return field, true
+}
+
+// error returns err, or an error wrapping it with a redacted message when the message changes.
+// Errors joining several errors, such as errors.Join ones, are wrapped by a redactedErrors.
+func (r *Redactor) error(err error) error {
+	msg := err.Error()
+	redacted := r.String(msg)
+	if redacted == msg {
+		return err
+	}
+
+	e := &redactedError{msg: redacted, err: err, r: r}
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		return &redactedErrors{redactedError: e}
+	}
+	return e
+}
+
+// reflected returns an object marshaling the maps logged with AddReflected, such as HTTP headers,
+// so that their keys can be redacted. Nil is returned for other types.
+func (r *Redactor) reflected(path string, value interface{}) zapcore.ObjectMarshaler {
+	var obj zapcore.ObjectMarshaler
+	switch v := value.(type) {
+	case http.Header:
+		obj = stringsMap(v)
+	case map[string][]string:
+		obj = stringsMap(v)
+	case map[string]string:
+		obj = stringMap(v)
+	default:
+		return nil
+	}
+	return &object{ObjectMarshaler: obj, r: r, prefix: path + "."}
+}
+
+// redactedField returns the field with its value redacted by strategy, or false when it's dropped.
+// Arrays keep their type, with every element redacted.
+func redactedField(field zapcore.Field, strategy Strategy) (zapcore.Field, bool) {
+	if field.Type == zapcore.ArrayMarshalerType {
+		if arr, ok := field.Interface.(zapcore.ArrayMarshaler); ok {
+			return zap.Array(field.Key, &redactedArray{ArrayMarshaler: arr, strategy: strategy}), true
+		}
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+
+	value, ok := strategy(fmt.Sprint(enc.Fields[field.Key]))
+	if !ok {
+		return field, false
+	}
+	return zap.String(field.Key, value), true
+}
+
+// redactedError is an error with a redacted message.
+// Its chain is redacted too, as error chains are reported to Sentry.
+type redactedError struct {
+	msg string
+	err error
+	r   *Redactor
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the redacted error wrapped by the original one, with Unwrap() error or Cause() error.
+func (e *redactedError) Unwrap() error {
+	var inner error
+	switch v := e.err.(type) {
+	case interface{ Unwrap() error }:
+		inner = v.Unwrap()
+	case interface{ Cause() error }:
+		inner = v.Cause()
+	}
+
+	if inner == nil {
+		return nil
+	}
+	return e.r.error(inner)
+}
+
+// TypeName returns the type of the original error, reported as the Sentry exception type.
+func (e *redactedError) TypeName() string {
+	return reflect.TypeOf(e.err).String()
+}
+
+// redactedErrors is a redactedError wrapping several errors, such as an errors.Join one.
+type redactedErrors struct {
+	*redactedError
+}
+
+// Unwrap returns the redacted errors wrapped by the original one.
+func (e *redactedErrors) Unwrap() []error {
+	joined, ok := e.err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+
+	errs := joined.Unwrap()
+	redacted := make([]error, 0, len(errs))
+	for _, inner := range errs {
+		if inner != nil {
+			redacted = append(redacted, e.r.error(inner))
+		}
+	}
+	return redacted
+}
+
+// stringMap marshals a map of strings with sorted keys.
+type stringMap map[string]string
+
+func (m stringMap) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, key := range sortedKeys(m) {
+		enc.AddString(key, m[key])
+	}
+	return nil
+}
+
+// stringsMap marshals a map of string slices with sorted keys.
+type stringsMap map[string][]string
+
+func (m stringsMap) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var errs []error
+	for _, key := range sortedKeys(m) {
+		errs = append(errs, enc.AddArray(key, zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, value := range m[key] {
+				arr.AppendString(value)
+			}
+			return nil
+		})))
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// object redacts the fields of an object when marshaled.
+type object struct {
+	zapcore.ObjectMarshaler
+
+	r      *Redactor
+	prefix string
+}
+
+func (o *object) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.ObjectMarshaler.MarshalLogObject(&objectEncoder{ObjectEncoder: enc, r: o.r, prefix: o.prefix})
+}
+
+// array redacts the elements of an array when marshaled.
+type array struct {
+	zapcore.ArrayMarshaler
+
+	r      *Redactor
+	prefix string
+}
+
+func (a *array) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.ArrayMarshaler.MarshalLogArray(&arrayEncoder{ArrayEncoder: enc, r: a.r, prefix: a.prefix})
+}
+
+// redactedArray redacts every element of an array with strategy.
+type redactedArray struct {
+	zapcore.ArrayMarshaler
+
+	strategy Strategy
+}
+
+func (a *redactedArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	values := zapcore.NewMapObjectEncoder()
+	if err := values.AddArray("values", a.ArrayMarshaler); err != nil {
+		return err
+	}
+
+	elements, _ := values.Fields["values"].([]interface{})
+	for _, element := range elements {
+		if value, ok := a.strategy(fmt.Sprint(element)); ok {
+			enc.AppendString(value)
+		}
+	}
+	return nil
+}
+
+// objectEncoder redacts the fields added to the wrapped encoder.
+type objectEncoder struct {
+	zapcore.ObjectEncoder
+
+	r      *Redactor
+	prefix string
+}
+
+// redactKey adds the value redacted by the key rule matching key, and returns whether a rule matched.
+func (e *objectEncoder) redactKey(key string, value func() string) bool {
+	strategy := e.r.keyStrategy(e.prefix + key)
+	if strategy == nil {
+		return false
+	}
+
+	if redacted, ok := strategy(value()); ok {
+		e.ObjectEncoder.AddString(key, redacted)
+	}
+	return true
+}
+
+func (e *objectEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	if strategy := e.r.keyStrategy(e.prefix + key); strategy != nil {
+		return e.ObjectEncoder.AddArray(key, &redactedArray{ArrayMarshaler: arr, strategy: strategy})
+	}
+	return e.ObjectEncoder.AddArray(key, &array{ArrayMarshaler: arr, r: e.r, prefix: e.prefix + key + "."})
+}
+
+func (e *objectEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	if e.redactKey(key, func() string { return marshaled(obj) }) {
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, &object{ObjectMarshaler: obj, r: e.r, prefix: e.prefix + key + "."})
+}
+
+func (e *objectEncoder) AddBinary(key string, value []byte) {
+	if !e.redactKey(key, func() string { return base64.StdEncoding.EncodeToString(value) }) {
+		e.ObjectEncoder.AddBinary(key, value)
+	}
+}
+
+func (e *objectEncoder) AddByteString(key string, value []byte) {
+	if !e.redactKey(key, func() string { return string(value) }) {
+		e.ObjectEncoder.AddString(key, e.r.String(string(value)))
+	}
+}
+
+func (e *objectEncoder) AddBool(key string, value bool) {
+	if !e.redactKey(key, func() string { return strconv.FormatBool(value) }) {
+		e.ObjectEncoder.AddBool(key, value)
+	}
+}
+
+func (e *objectEncoder) AddComplex128(key string, value complex128) {
+	if !e.redactKey(key, func() string { return strconv.FormatComplex(value, 'g', -1, 128) }) {
+		e.ObjectEncoder.AddComplex128(key, value)
+	}
+}
+
+func (e *objectEncoder) AddComplex64(key string, value complex64) {
+	if !e.redactKey(key, func() string { return strconv.FormatComplex(complex128(value), 'g', -1, 64) }) {
+		e.ObjectEncoder.AddComplex64(key, value)
+	}
+}
+
+func (e *objectEncoder) AddDuration(key string, value time.Duration) {
+	if !e.redactKey(key, value.String) {
+		e.ObjectEncoder.AddDuration(key, value)
+	}
+}
+
+func (e *objectEncoder) AddFloat64(key string, value float64) {
+	if !e.redactKey(key, func() string { return strconv.FormatFloat(value, 'g', -1, 64) }) {
+		e.ObjectEncoder.AddFloat64(key, value)
+	}
+}
+
+func (e *objectEncoder) AddFloat32(key string, value float32) {
+	if !e.redactKey(key, func() string { return strconv.FormatFloat(float64(value), 'g', -1, 32) }) {
+		e.ObjectEncoder.AddFloat32(key, value)
+	}
+}
+
+func (e *objectEncoder) AddInt(key string, value int) { e.AddInt64(key, int64(value)) }
+
+func (e *objectEncoder) AddInt64(key string, value int64) {
+	if !e.redactKey(key, func() string { return strconv.FormatInt(value, 10) }) {
+		e.ObjectEncoder.AddInt64(key, value)
+	}
+}
+
+func (e *objectEncoder) AddInt32(key string, value int32) { e.AddInt64(key, int64(value)) }
+
+func (e *objectEncoder) AddInt16(key string, value int16) { e.AddInt64(key, int64(value)) }
+
+func (e *objectEncoder) AddInt8(key string, value int8) { e.AddInt64(key, int64(value)) }
+
+func (e *objectEncoder) AddString(key, value string) {
+	if !e.redactKey(key, func() string { return value }) {
+		e.ObjectEncoder.AddString(key, e.r.String(value))
+	}
+}
+
+func (e *objectEncoder) AddTime(key string, value time.Time) {
+	if !e.redactKey(key, func() string { return value.Format(time.RFC3339Nano) }) {
+		e.ObjectEncoder.AddTime(key, value)
+	}
+}
+
+func (e *objectEncoder) AddUint(key string, value uint) { e.AddUint64(key, uint64(value)) }
+
+func (e *objectEncoder) AddUint64(key string, value uint64) {
+	if !e.redactKey(key, func() string { return strconv.FormatUint(value, 10) }) {
+		e.ObjectEncoder.AddUint64(key, value)
+	}
+}
+
+func (e *objectEncoder) AddUint32(key string, value uint32) { e.AddUint64(key, uint64(value)) }
+
+func (e *objectEncoder) AddUint16(key string, value uint16) { e.AddUint64(key, uint64(value)) }
+
+func (e *objectEncoder) AddUint8(key string, value uint8) { e.AddUint64(key, uint64(value)) }
+
+func (e *objectEncoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+
+func (e *objectEncoder) AddReflected(key string, value interface{}) error {
+	if e.redactKey(key, func() string { return fmt.Sprint(value) }) {
+		return nil
+	}
+	if obj := e.r.reflected(e.prefix+key, value); obj != nil {
+		return e.ObjectEncoder.AddObject(key, obj)
+	}
+	if s, ok := value.(string); ok {
+		value = e.r.String(s)
+	}
+	return e.ObjectEncoder.AddReflected(key, value)
+}
+
+func (e *objectEncoder) OpenNamespace(key string) {
+	e.prefix += key + "."
+	e.ObjectEncoder.OpenNamespace(key)
+}
+
+// arrayEncoder redacts the elements appended to the wrapped encoder.
+// Elements have the array path, e.g. the elements of users are users.email objects.
+type arrayEncoder struct {
+	zapcore.ArrayEncoder
+
+	r      *Redactor
+	prefix string
+}
+
+func (e *arrayEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(&array{ArrayMarshaler: arr, r: e.r, prefix: e.prefix})
+}
+
+func (e *arrayEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(&object{ObjectMarshaler: obj, r: e.r, prefix: e.prefix})
+}
+
+func (e *arrayEncoder) AppendByteString(value []byte) {
+	e.ArrayEncoder.AppendString(e.r.String(string(value)))
+}
+
+func (e *arrayEncoder) AppendString(value string) {
+	e.ArrayEncoder.AppendString(e.r.String(value))
+}
+
+func (e *arrayEncoder) AppendReflected(value interface{}) error {
+	if s, ok := value.(string); ok {
+		value = e.r.String(s)
+	}
+	return e.ArrayEncoder.AppendReflected(value)
+}
+
+// marshaled returns the JSON-like representation of an object, hashed by the Hash strategy.
+func marshaled(obj zapcore.ObjectMarshaler) string {
+	enc := zapcore.NewMapObjectEncoder()
+	if err := obj.MarshalLogObject(enc); err != nil {
+		return err.Error()
+	}
+	return fmt.Sprint(enc.Fields)
+}
@@ -0,0 +1,157 @@
+// Package redact provides rules masking sensitive values, such as credentials and personal data, from log entries.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Masked replaces the values redacted by Mask.
+const Masked = "[REDACTED]"
+
+// Strategy returns the replacement of a sensitive value, or false to drop it.
+type Strategy func(value string) (string, bool)
+
+// Mask is a Strategy replacing values with Masked.
+func Mask(string) (string, bool) {
+	return Masked, true
+}
+
+// Hash is a Strategy replacing values with their SHA-256 hash, so that they can still be correlated.
+func Hash(value string) (string, bool) {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:]), true
+}
+
+// Drop is a Strategy removing values. Fields are removed, and value matches are removed from strings.
+func Drop(string) (string, bool) {
+	return "", false
+}
+
+// Rule redacts the values of matching keys, or the matches of a pattern in string values.
+type Rule struct {
+	keys     []string
+	pattern  *regexp.Regexp
+	strategy Strategy
+}
+
+// Keys returns a Rule redacting the values of keys, matched case-insensitively.
+// A key matches the field with that key, or with a dotted path ending with that key:
+// "authorization" matches "http.request.headers.Authorization", and "user.email" matches "user.email".
+func Keys(strategy Strategy, keys ...string) Rule {
+	lower := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lower = append(lower, strings.ToLower(key))
+	}
+	return Rule{keys: lower, strategy: strategy}
+}
+
+// Pattern returns a Rule redacting the matches of pattern in string values and messages.
+func Pattern(strategy Strategy, pattern *regexp.Regexp) Rule {
+	return Rule{pattern: pattern, strategy: strategy}
+}
+
+// Emails returns a Rule redacting email addresses.
+func Emails(strategy Strategy) Rule {
+	return Pattern(strategy, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`))
+}
+
+// CreditCards returns a Rule redacting credit card numbers, with optional space or dash separators.
+func CreditCards(strategy Strategy) Rule {
+	return Pattern(strategy, regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`))
+}
+
+// JWTs returns a Rule redacting JSON Web Tokens.
+func JWTs(strategy Strategy) Rule {
+	return Pattern(strategy, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`))
+}
+
+// SensitiveHeaders returns the names of the HTTP headers carrying credentials.
+func SensitiveHeaders() []string {
+	return []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-Api-Key", "X-Auth-Token"}
+}
+
+// Headers returns a copy of h with the values of the sensitive headers masked.
+// h is returned as is when it has no sensitive header.
+func Headers(h http.Header) http.Header {
+	var masked http.Header
+	for _, name := range SensitiveHeaders() {
+		values, ok := h[name]
+		if !ok {
+			continue
+		}
+
+		if masked == nil {
+			masked = h.Clone()
+		}
+		masked[name] = make([]string, len(values))
+		for i := range values {
+			masked[name][i] = Masked
+		}
+	}
+
+	if masked == nil {
+		return h
+	}
+	return masked
+}
+
+// Redactor applies rules to log fields.
+type Redactor struct {
+	keyRules   []Rule
+	valueRules []Rule
+}
+
+// New returns a Redactor applying rules. Rules without strategy mask values.
+func New(rules ...Rule) *Redactor {
+	r := &Redactor{}
+	r.Add(rules...)
+	return r
+}
+
+// Add adds rules to the Redactor. It must not be called once the Redactor is in use.
+func (r *Redactor) Add(rules ...Rule) {
+	for _, rule := range rules {
+		if rule.strategy == nil {
+			rule.strategy = Mask
+		}
+
+		if rule.pattern != nil {
+			r.valueRules = append(r.valueRules, rule)
+		}
+		if len(rule.keys) > 0 {
+			r.keyRules = append(r.keyRules, rule)
+		}
+	}
+}
+
+// String returns value with the matches of the pattern rules redacted.
+func (r *Redactor) String(value string) string {
+	for _, rule := range r.valueRules {
+		value = rule.pattern.ReplaceAllStringFunc(value, func(match string) string {
+			replacement, _ := rule.strategy(match)
+			return replacement
+		})
+	}
+	return value
+}
+
+// keyStrategy returns the strategy of the first key rule matching the dotted path, or nil.
+func (r *Redactor) keyStrategy(path string) Strategy {
+	if len(r.keyRules) == 0 {
+		return nil
+	}
+
+	path = strings.ToLower(path)
+	for _, rule := range r.keyRules {
+		for _, key := range rule.keys {
+			if path == key || strings.HasSuffix(path, "."+key) {
+				return rule.strategy
+			}
+		}
+	}
+	return nil
+}
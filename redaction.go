@@ -0,0 +1,16 @@
+package log
+
+import (
+	"go.pixelfactory.io/pkg/observability/log/redact"
+)
+
+// WithRedaction redacts the messages and fields matching rules, both in the output and in Sentry events.
+// It may be used several times, rules are added to the previous ones.
+func WithRedaction(rules ...redact.Rule) Option {
+	return func(l *DefaultLogger) {
+		if l.config.redactor == nil {
+			l.config.redactor = redact.New()
+		}
+		l.config.redactor.Add(rules...)
+	}
+}
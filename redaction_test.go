@@ -0,0 +1,85 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.pixelfactory.io/pkg/observability/log"
+	"go.pixelfactory.io/pkg/observability/log/fields"
+	"go.pixelfactory.io/pkg/observability/log/redact"
+)
+
+// transport captures the Sentry events.
+type transport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *transport) Flush(time.Duration) bool              { return true }
+func (t *transport) FlushWithContext(context.Context) bool { return true }
+func (t *transport) Configure(sentry.ClientOptions)        {}
+func (t *transport) Close()                                {}
+func (t *transport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func Test_WithRedaction(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+	tr := &transport{}
+
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.test/1", Transport: tr})
+	is.NoError(err)
+
+	logger, err := log.Build(
+		log.WithOutput(&buf),
+		log.WithSentry(client),
+		log.WithRedaction(redact.Emails(redact.Mask)),
+		log.WithRedaction(redact.Keys(redact.Drop, "password")),
+	)
+	is.NoError(err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://test/foo", http.NoBody)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	logger.Error("failed",
+		zap.String("user.email", "john@example.com"),
+		zap.String("password", "secret"),
+		fields.HTTPRequest(req),
+		fields.Service("api", "1.2.0"),
+		fields.Error(fmt.Errorf("user john@example.com: %w", fs.ErrNotExist)),
+	)
+
+	is.Contains(buf.String(), `"user.email":"[REDACTED]"`)
+	is.Contains(buf.String(), `"Authorization":["[REDACTED]"]`)
+	is.NotContains(buf.String(), "password")
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	is.Len(tr.events, 1)
+	is.Equal(redact.Masked, tr.events[0].User.Email)
+	is.Equal(redact.Masked, tr.events[0].Request.Headers["Authorization"])
+	is.NotContains(tr.events[0].Tags, "password")
+	is.Equal("api@1.2.0", tr.events[0].Release)
+	is.Equal("api", tr.events[0].Tags["service.name"])
+
+	exceptions := tr.events[0].Exception
+	is.Len(exceptions, 2)
+	is.Equal("*fmt.wrapError", exceptions[1].Type)
+	is.Equal("user [REDACTED]: file does not exist", exceptions[1].Value)
+	is.Equal("*errors.errorString", exceptions[0].Type)
+}
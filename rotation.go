@@ -0,0 +1,402 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+	fileMode         = 0o600
+	dirMode          = 0o750
+)
+
+// RotationConfig configures the rotation and retention of a log file.
+// Zero values disable the matching rotation or retention rule.
+type RotationConfig struct {
+	// MaxSize is the size in bytes a log file can reach before being rotated.
+	MaxSize int64
+	// Interval is the maximum time a log file is written to before being rotated.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int
+	// MaxAge is the duration rotated files are kept for.
+	MaxAge time.Duration
+	// Compress gzips rotated files.
+	Compress bool
+}
+
+// WithRotatingFile writes log entries to the file at path, rotated according to cfg.
+// Rotated files are renamed with their rotation time, e.g. app-2006-01-02T15-04-05.000.log.
+// The file is reopened on SIGHUP, so that it can also be rotated by logrotate.
+// It is closed by Close. A file that can't be opened makes Build return an error.
+func WithRotatingFile(path string, cfg RotationConfig) Option {
+	return func(l *DefaultLogger) {
+		f, err := newRotatingFile(path, cfg)
+		if err != nil {
+			l.config.errs = append(l.config.errs, err)
+			return
+		}
+
+		l.config.output = f
+		l.config.terminal = false
+		l.closers.add(f.Close)
+	}
+}
+
+// rotatingFile is a zapcore.WriteSyncer rotating the file it writes to.
+// It is safe for concurrent use.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	cfg      RotationConfig
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	// mill triggers the compression and removal of rotated files.
+	mill chan struct{}
+	// reopen receives the signals reopening the file.
+	reopen chan os.Signal
+	done   chan struct{}
+	wg     sync.WaitGroup
+	closed bool
+
+	// errorOutput receives the rotation and reopen failures the file recovers from.
+	errorOutput zapcore.WriteSyncer
+}
+
+func newRotatingFile(path string, cfg RotationConfig) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:   path,
+		cfg:    cfg,
+		mill:   make(chan struct{}, 1),
+		reopen: make(chan os.Signal, 1),
+		done:   make(chan struct{}),
+
+		errorOutput: zapcore.Lock(os.Stderr),
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	notifyReopen(f.reopen)
+	f.wg.Add(2)
+	go f.runMill()
+	go f.handleReopen()
+
+	return f, nil
+}
+
+// Write writes p to the file, rotating it first when needed.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Sync commits the file content to stable storage.
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+// Reopen opens the file at path again, e.g. after it has been moved by an external
+// rotation tool, then closes the previous file. The previous file is kept when the
+// file at path can't be opened.
+func (f *rotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.reopenFile()
+}
+
+// setErrorOutput sets the output of the failures the file recovers from.
+func (f *rotatingFile) setErrorOutput(ws zapcore.WriteSyncer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errorOutput = ws
+}
+
+// Close closes the file, once pending compressions and removals are done.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	err := f.file.Close()
+	f.mu.Unlock()
+
+	signal.Stop(f.reopen)
+	close(f.done)
+	f.wg.Wait()
+	return err
+}
+
+// reopenFile opens the file at path, and swaps it with the previous file on success.
+func (f *rotatingFile) reopenFile() error {
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	previous := f.file
+	if err := f.open(); err != nil {
+		return err
+	}
+	return previous.Close()
+}
+
+// open opens the file at path in append mode. The file is only replaced on success.
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), dirMode); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+// reportError writes a failure the file recovers from to the error output, as zap reports write errors.
+func (f *rotatingFile) reportError(msg string, err error) {
+	_, _ = fmt.Fprintf(f.errorOutput, "%v %s: %v\n", time.Now().UTC(), msg, err)
+	_ = f.errorOutput.Sync()
+}
+
+func (f *rotatingFile) shouldRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.cfg.MaxSize > 0 && f.size+n > f.cfg.MaxSize {
+		return true
+	}
+	return f.cfg.Interval > 0 && time.Since(f.openedAt) >= f.cfg.Interval
+}
+
+// rotate renames the file with the current time and opens a new one.
+// The file is closed first, as open files can't be renamed on Windows. When the file
+// can't be renamed or the new one opened, the file at path is reopened, and the failure
+// is reported to the error output.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		f.reportError("log file rotation close error", err)
+	}
+
+	err := os.Rename(f.path, f.backupPath())
+	if err == nil {
+		err = f.open()
+	}
+	if err != nil {
+		if reopenErr := f.open(); reopenErr != nil {
+			return errors.Join(err, reopenErr)
+		}
+		f.reportError("log file rotation error", err)
+		// Rotation is retried once the size or interval limit is reached again.
+		f.size = 0
+		return nil
+	}
+
+	select {
+	case f.mill <- struct{}{}:
+	default:
+		// A mill run is already pending.
+	}
+	return nil
+}
+
+// backupPath returns an unused rotated file path, named with the current time.
+func (f *rotatingFile) backupPath() string {
+	prefix, ext := f.nameParts()
+	rotatedAt := time.Now().UTC()
+	for {
+		path := filepath.Join(filepath.Dir(f.path), prefix+rotatedAt.Format(backupTimeFormat)+ext)
+		if !exists(path) && !exists(path+compressSuffix) {
+			return path
+		}
+		// Files rotated within the same millisecond get distinct names.
+		rotatedAt = rotatedAt.Add(time.Millisecond)
+	}
+}
+
+// nameParts returns the prefix and the extension of the rotated file names.
+func (f *rotatingFile) nameParts() (string, string) {
+	name := filepath.Base(f.path)
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-", ext
+}
+
+func (f *rotatingFile) handleReopen() {
+	defer f.wg.Done()
+
+	for {
+		select {
+		case <-f.reopen:
+			f.mu.Lock()
+			if err := f.reopenFile(); err != nil && !f.closed {
+				f.reportError("log file reopen error", err)
+			}
+			f.mu.Unlock()
+		case <-f.done:
+			return
+		}
+	}
+}
+
+func (f *rotatingFile) runMill() {
+	defer f.wg.Done()
+
+	for {
+		select {
+		case <-f.mill:
+			f.millBackups()
+		case <-f.done:
+			select {
+			case <-f.mill:
+				f.millBackups()
+			default:
+			}
+			return
+		}
+	}
+}
+
+// backup is a rotated file.
+type backup struct {
+	path       string
+	rotatedAt  time.Time
+	compressed bool
+}
+
+// millBackups removes the rotated files exceeding MaxBackups or MaxAge, and compresses the others.
+// Errors are ignored, the files are handled again on the next rotation.
+func (f *rotatingFile) millBackups() {
+	backups := f.backups()
+
+	// Newest first.
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].rotatedAt.After(backups[j].rotatedAt)
+	})
+
+	cutoff := time.Now().Add(-f.cfg.MaxAge)
+	for i, b := range backups {
+		if (f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups) || (f.cfg.MaxAge > 0 && b.rotatedAt.Before(cutoff)) {
+			_ = os.Remove(b.path)
+			continue
+		}
+
+		if f.cfg.Compress && !b.compressed {
+			_ = compress(b.path)
+		}
+	}
+}
+
+// backups lists the rotated files of the file.
+func (f *rotatingFile) backups() []backup {
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return nil
+	}
+
+	prefix, ext := f.nameParts()
+	backups := make([]backup, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		compressed := strings.HasSuffix(name, ext+compressSuffix)
+		if !compressed && !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		timestamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressSuffix), ext)
+		rotatedAt, parseErr := time.Parse(backupTimeFormat, timestamp)
+		if parseErr != nil {
+			continue
+		}
+
+		backups = append(backups, backup{
+			path:       filepath.Join(filepath.Dir(f.path), name),
+			rotatedAt:  rotatedAt,
+			compressed: compressed,
+		})
+	}
+	return backups
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// compress gzips the file at path and removes it.
+func compress(path string) error {
+	src, err := os.Open(path) //nolint:gosec // path is a rotated file listed from the log directory.
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fileMode)
+	if err != nil {
+		_ = src.Close()
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	// The source is closed before being removed, as required on Windows.
+	err = errors.Join(err, gz.Close(), dst.Close(), src.Close())
+	if err != nil {
+		_ = os.Remove(path + compressSuffix)
+		return err
+	}
+
+	return os.Remove(path)
+}
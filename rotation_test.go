@@ -0,0 +1,119 @@
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+// logFiles returns the names of the files in dir.
+func logFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func Test_WithRotatingFile(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	dir := t.TempDir()
+
+	logger, err := log.Build(log.WithRotatingFile(filepath.Join(dir, "app.log"), log.RotationConfig{
+		MaxSize:    1,
+		MaxBackups: 2,
+	}))
+	is.NoError(err)
+
+	for range 5 {
+		logger.Info(message)
+	}
+	is.NoError(logger.Close())
+
+	names := logFiles(t, dir)
+	is.Len(names, 3)
+	is.Contains(names, "app.log")
+	for _, name := range names {
+		is.True(strings.HasPrefix(name, "app"))
+		is.True(strings.HasSuffix(name, ".log"))
+	}
+}
+
+func Test_WithRotatingFile_Compress(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	dir := t.TempDir()
+
+	logger, err := log.Build(log.WithRotatingFile(filepath.Join(dir, "app.log"), log.RotationConfig{
+		MaxSize:  1,
+		Compress: true,
+	}))
+	is.NoError(err)
+
+	logger.Info(message)
+	logger.Info(message)
+	is.NoError(logger.Close())
+
+	names := logFiles(t, dir)
+	is.Len(names, 2)
+	is.Contains(names, "app.log")
+	for _, name := range names {
+		if name != "app.log" {
+			is.True(strings.HasSuffix(name, ".log.gz"))
+		}
+	}
+}
+
+func Test_WithRotatingFile_Concurrent(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	dir := t.TempDir()
+
+	logger, err := log.Build(log.WithRotatingFile(filepath.Join(dir, "app.log"), log.RotationConfig{
+		MaxSize: 1024,
+	}))
+	is.NoError(err)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				logger.Info(message)
+			}
+		}()
+	}
+	wg.Wait()
+	is.NoError(logger.Close())
+
+	lines := 0
+	for _, name := range logFiles(t, dir) {
+		content, readErr := os.ReadFile(filepath.Join(dir, name))
+		is.NoError(readErr)
+		lines += strings.Count(string(content), message)
+	}
+	is.Equal(200, lines)
+}
+
+func Test_WithRotatingFile_Error(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	is.NoError(os.Mkdir(path, 0o750))
+
+	_, err := log.Build(log.WithRotatingFile(path, log.RotationConfig{}))
+	is.Error(err)
+}
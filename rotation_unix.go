@@ -0,0 +1,14 @@
+//go:build !windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReopen relays SIGHUP to c, as sent by logrotate after moving a log file.
+func notifyReopen(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGHUP)
+}
@@ -0,0 +1,112 @@
+//go:build !windows
+
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+//nolint:paralleltest // SIGHUP reopens the files of every logger.
+func Test_WithRotatingFile_Reopen(t *testing.T) {
+	is := require.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	logger, err := log.Build(log.WithRotatingFile(path, log.RotationConfig{}))
+	is.NoError(err)
+	defer logger.Close()
+
+	// Rotate the file as logrotate does.
+	is.NoError(os.Rename(path, filepath.Join(dir, "app.log.1")))
+	is.NoError(syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	is.Eventually(func() bool {
+		_, statErr := os.Stat(path)
+		return statErr == nil
+	}, time.Second, 10*time.Millisecond)
+
+	logger.Info(message)
+	content, err := os.ReadFile(path)
+	is.NoError(err)
+	is.Contains(string(content), message)
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+//nolint:paralleltest // SIGHUP reopens the files of every logger.
+func Test_WithRotatingFile_ReopenError(t *testing.T) {
+	is := require.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	var errOutput lockedBuffer
+
+	logger, err := log.Build(log.WithRotatingFile(path, log.RotationConfig{}), log.WithErrorOutput(&errOutput))
+	is.NoError(err)
+	defer logger.Close()
+
+	// The file can't be reopened, as a directory replaced it.
+	is.NoError(os.Rename(path, filepath.Join(dir, "app.log.1")))
+	is.NoError(os.Mkdir(path, 0o750))
+	is.NoError(syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	is.Eventually(func() bool {
+		return strings.Contains(errOutput.String(), "log file reopen error")
+	}, time.Second, 10*time.Millisecond)
+
+	// The previous file is still written to.
+	logger.Info(message)
+	content, err := os.ReadFile(filepath.Join(dir, "app.log.1"))
+	is.NoError(err)
+	is.Contains(string(content), message)
+}
+
+func Test_WithRotatingFile_RotationError(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	var errOutput bytes.Buffer
+
+	logger, err := log.Build(
+		log.WithRotatingFile(path, log.RotationConfig{MaxSize: 1}),
+		log.WithErrorOutput(&errOutput),
+	)
+	is.NoError(err)
+	defer logger.Close()
+
+	logger.Info(message)
+	// The file can't be renamed, as it has been removed.
+	is.NoError(os.Remove(path))
+	logger.Info("after rotation error")
+
+	is.Contains(errOutput.String(), "log file rotation error")
+	content, err := os.ReadFile(path)
+	is.NoError(err)
+	is.Contains(string(content), "after rotation error")
+}
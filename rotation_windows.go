@@ -0,0 +1,8 @@
+//go:build windows
+
+package log
+
+import "os"
+
+// notifyReopen is a no-op, Windows has no SIGHUP.
+func notifyReopen(chan<- os.Signal) {}
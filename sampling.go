@@ -0,0 +1,143 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log/ratelimit"
+)
+
+// SamplingConfig configures the sampling of log entries, see WithSampling.
+type SamplingConfig struct {
+	// Initial is the number of entries with the same level and message logged per tick.
+	Initial int `json:"initial" yaml:"initial"`
+	// Thereafter is the sampling rate of the next entries, 1 out of Thereafter is logged.
+	// Zero drops every next entry.
+	Thereafter int `json:"thereafter" yaml:"thereafter"`
+	// Tick is the sampling period, and the period of the dropped entries summary, e.g. "1s".
+	Tick Duration `json:"tick" yaml:"tick"`
+}
+
+// WithSampling samples entries below the error level: within each tick, the first
+// initial entries with the same level and message are logged, then 1 out of thereafter.
+// Errors, and entries sent to Sentry, are never sampled.
+// Dropped entries are counted per level and reported by a warning every tick.
+func WithSampling(initial, thereafter int, tick time.Duration) Option {
+	return func(l *DefaultLogger) {
+		if tick <= 0 {
+			l.config.errs = append(l.config.errs, fmt.Errorf("invalid sampling tick: %s", tick))
+			return
+		}
+
+		l.config.sampling = &SamplingConfig{
+			Initial:    initial,
+			Thereafter: thereafter,
+			Tick:       Duration(tick),
+		}
+	}
+}
+
+// WithRateLimit rate limits entries with a token bucket per key, of burst tokens
+// refilled at rate tokens per second. Entries are keyed by message by default, see
+// ratelimit.WithKeyFunc. The number of suppressed entries is added to the next entry
+// with the same key. Like sampling, rate limiting doesn't apply to entries sent to Sentry.
+func WithRateLimit(rate float64, burst int, opts ...ratelimit.Option) Option {
+	return func(l *DefaultLogger) {
+		l.config.rateLimit = func(core zapcore.Core) zapcore.Core {
+			return ratelimit.NewCore(core, rate, burst, opts...)
+		}
+	}
+}
+
+// sampler counts the entries dropped by sampling, and periodically logs their summary.
+type sampler struct {
+	dropped [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Uint64
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newSampledCore returns a core sampling the entries below the error level written to core.
+func newSampledCore(core zapcore.Core, cfg *SamplingConfig, s *sampler) zapcore.Core {
+	return &exemptCore{
+		Core: core,
+		sampled: zapcore.NewSamplerWithOptions(core, time.Duration(cfg.Tick), cfg.Initial, cfg.Thereafter,
+			zapcore.SamplerHook(s.hook)),
+	}
+}
+
+// start logs the dropped entries summary every tick, until Close is called.
+func (s *sampler) start(logger *zap.Logger, tick time.Duration, c *closers) {
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.summary(logger)
+			case <-s.done:
+				s.summary(logger)
+				return
+			}
+		}
+	}()
+
+	c.add(func() error {
+		close(s.done)
+		s.wg.Wait()
+		return nil
+	})
+}
+
+func (s *sampler) hook(entry zapcore.Entry, decision zapcore.SamplingDecision) {
+	if decision&zapcore.LogDropped != 0 && entry.Level >= zapcore.DebugLevel && entry.Level <= zapcore.FatalLevel {
+		s.dropped[entry.Level-zapcore.DebugLevel].Add(1)
+	}
+}
+
+// summary logs and resets the number of dropped entries per level.
+func (s *sampler) summary(logger *zap.Logger) {
+	var total uint64
+	fs := make([]zapcore.Field, 0, len(s.dropped))
+	for i := range s.dropped {
+		if n := s.dropped[i].Swap(0); n > 0 {
+			total += n
+			fs = append(fs, zap.Uint64((zapcore.DebugLevel+zapcore.Level(i)).String(), n))
+		}
+	}
+
+	if total > 0 {
+		logger.Warn(fmt.Sprintf("%d messages dropped", total), zap.Dict("log.sampling.dropped", fs...))
+	}
+}
+
+// exemptCore writes the entries at or above the error level to Core, and the others to the sampled core.
+type exemptCore struct {
+	zapcore.Core
+
+	sampled zapcore.Core
+}
+
+// With adds structured context to both cores.
+func (c *exemptCore) With(fields []zapcore.Field) zapcore.Core {
+	return &exemptCore{
+		Core:    c.Core.With(fields),
+		sampled: c.sampled.With(fields),
+	}
+}
+
+// Check delegates to the sampled core below the error level.
+func (c *exemptCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level >= zapcore.ErrorLevel {
+		return c.Core.Check(entry, checked)
+	}
+	return c.sampled.Check(entry, checked)
+}
@@ -0,0 +1,52 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pixelfactory.io/pkg/observability/log"
+)
+
+func Test_WithSampling(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger, err := log.Build(log.WithSampling(1, 0, time.Hour), log.WithOutput(&buf))
+	is.NoError(err)
+
+	for range 5 {
+		logger.Info(message)
+		logger.Error(message)
+	}
+	is.NoError(logger.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	is.Len(lines, 7)
+
+	var summary map[string]interface{}
+	is.NoError(json.Unmarshal([]byte(lines[6]), &summary))
+	is.Equal("4 messages dropped", summary["message"])
+	is.Equal(map[string]interface{}{"info": float64(4)}, summary["log.sampling.dropped"])
+
+	_, err = log.Build(log.WithSampling(1, 0, 0))
+	is.Error(err)
+}
+
+func Test_WithRateLimit(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger := log.New(log.WithRateLimit(0, 1), log.WithOutput(&buf))
+	for range 3 {
+		logger.Error(message)
+	}
+
+	is.Equal(1, strings.Count(buf.String(), message))
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/getsentry/sentry-go"
-	"github.com/pkg/errors"
 	"go.uber.org/zap/zapcore"
 
 	ecsfields "go.pixelfactory.io/pkg/observability/log/fields"
@@ -33,9 +32,18 @@ var zapLevelToSentrySeverity = map[zapcore.Level]sentry.Level{
 const errorKey = "error"
 
 // serviceKey is zap.Field key for fields.Service.
-// https://github.com/elastic/ecs-logging-go-zap/blob/master/internal/error.go
+// Its fields are read once encoded, as the field may be wrapped, e.g. by a redact.Core.
 const serviceKey = "service"
 
+// hubKey is zap.Field key for Hub.
+const hubKey = "sentry.hub"
+
+// Hub returns a field making the Core capture the entry with the scope of hub, such as
+// a request-scoped hub, rather than the current hub. The field isn't encoded by other cores.
+func Hub(hub *sentry.Hub) zapcore.Field {
+	return zapcore.Field{Key: hubKey, Type: zapcore.SkipType, Interface: hub}
+}
+
 // Option type.
 type Option func(*Core)
 
@@ -43,6 +51,10 @@ type Option func(*Core)
 // sentry.Flush() when calling Core.Sync().
 const DefaultSentryFlushTimeout = 5 * time.Second
 
+// DefaultMaxErrorDepth is the number of errors of a chain reported as exceptions,
+// when the MaxErrorDepth client option is unset, as for clients not created by sentry.NewClient.
+const DefaultMaxErrorDepth = 100
+
 // SetFlushTimeout set sentry flush timeout.
 func SetFlushTimeout(timeout time.Duration) Option {
 	return func(core *Core) {
@@ -50,12 +62,37 @@ func SetFlushTimeout(timeout time.Duration) Option {
 	}
 }
 
+// SetBreadcrumbLevel records the entries enabled by enab, but not by the Core level, as Sentry
+// breadcrumbs, attached to the next events. Breadcrumbs have the logger name as category,
+// and the entry fields as data.
+func SetBreadcrumbLevel(enab zapcore.LevelEnabler) Option {
+	return func(core *Core) {
+		core.breadcrumbLevel = enab
+	}
+}
+
+// Fingerprinter returns the fingerprint of the Sentry event of an entry, grouping the events
+// with the same fingerprint, or nil for the default grouping.
+// Fields include the context fields added with With.
+type Fingerprinter func(entry zapcore.Entry, fields []zapcore.Field) []string
+
+// WithFingerprinter sets the fingerprint of the events without fields.Fingerprint field.
+func WithFingerprinter(fingerprinter Fingerprinter) Option {
+	return func(core *Core) {
+		core.fingerprinter = fingerprinter
+	}
+}
+
 // Core struct.
 type Core struct {
 	zapcore.LevelEnabler
 
 	client             *sentry.Client
 	sentryFlushTimeout time.Duration
+	breadcrumbLevel    zapcore.LevelEnabler
+	fingerprinter      Fingerprinter
+	tagKeys            map[string]bool
+	extraKeys          map[string]bool
 	fields             []zapcore.Field
 }
 
@@ -88,6 +125,11 @@ func (c *Core) With(fields []zapcore.Field) zapcore.Core {
 	return &clone
 }
 
+// Enabled reports whether entries at level are sent as events, or recorded as breadcrumbs.
+func (c *Core) Enabled(level zapcore.Level) bool {
+	return c.LevelEnabler.Enabled(level) || (c.breadcrumbLevel != nil && c.breadcrumbLevel.Enabled(level))
+}
+
 // Check verifies whether or not the provided entry should be logged,
 // by comparing the log level with the configured log level in the core.
 // If it should be logged the core is added to the returned entry.
@@ -102,6 +144,11 @@ func (c *Core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcor
 //
 //nolint:gocognit // Core function for Sentry integration requires complex field processing
 func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.LevelEnabler.Enabled(entry.Level) {
+		c.addBreadcrumb(entry, fields)
+		return nil
+	}
+
 	// Create a Sentry Event.
 	event := sentry.NewEvent()
 	event.Message = entry.Message
@@ -116,16 +163,28 @@ func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
 
 	// When set, relevant Sentry interfaces are added.
 	var err error
-	var svc *ecsfields.ServiceField
+	hub := sentry.CurrentHub()
 
 	// processField processes the given field.
 	// When false is returned, the whole entry is to be skipped.
 	processField := func(field zapcore.Field) bool {
-		// Look for "service" key.
+		// Look for "sentry.hub" key.
 		switch field.Key {
-		case serviceKey:
-			if s, ok := field.Interface.(*ecsfields.ServiceField); ok {
-				svc = s
+		case hubKey:
+			if h, ok := field.Interface.(*sentry.Hub); ok && h != nil {
+				hub = h
+			} else {
+				field.AddTo(encoder)
+			}
+
+		// Look for "sentry.fingerprint" key.
+		case ecsfields.FingerprintKey:
+			event.Fingerprint = fieldStrings(field)
+
+		// Look for "sentry.transaction" key.
+		case ecsfields.TransactionKey:
+			if field.Type == zapcore.StringType {
+				event.Transaction = field.String
 			} else {
 				field.AddTo(encoder)
 			}
@@ -161,21 +220,23 @@ func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
 		}
 	}
 
+	// Fingerprint fields take precedence over the fingerprinter.
+	if event.Fingerprint == nil && c.fingerprinter != nil {
+		all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+		event.Fingerprint = c.fingerprinter(entry, append(append(all, c.fields...), fields...))
+	}
+
 	// Process error
 	if err != nil {
-		// In case an error object is present, create an exception.
-		// Capture the stack trace in any case.
-		stacktrace := sentry.ExtractStacktrace(err)
-		if stacktrace == nil {
-			stacktrace = sentry.NewStacktrace()
+		// In case an error object is present, create an exception per error of the chain,
+		// walking Unwrap() error, Unwrap() []error and Cause() error, outermost last.
+		event.SetException(err, c.maxErrorDepth())
+		setExceptionTypes(event, err, c.maxErrorDepth())
+		// The outermost exception gets the current stack trace when the error has none.
+		if outer := &event.Exception[len(event.Exception)-1]; sentry.ExtractStacktrace(err) == nil &&
+			outer.Stacktrace != nil {
+			outer.Stacktrace.Frames = filterFrames(outer.Stacktrace.Frames)
 		}
-		// Handle wrapped errors for github.com/pingcap/errors and github.com/pkg/errors
-		cause := errors.Cause(err)
-		event.Exception = []sentry.Exception{{
-			Value:      cause.Error(),
-			Type:       reflect.TypeOf(cause).String(),
-			Stacktrace: stacktrace,
-		}}
 	} else {
 		stacktrace := sentry.NewStacktrace()
 		stacktrace.Frames = filterFrames(stacktrace.Frames)
@@ -185,34 +246,33 @@ func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
 		}}
 	}
 
-	// fields into tags.
-	tags := make(map[string]string)
+	// fields into interfaces, tags and extra.
+	c.setInterfaces(event, entry.Level, encoder.Fields)
 
-	// Process service
-	if svc != nil {
-		tags["service.name"] = svc.Name
-		tags["service.version"] = svc.Version
-	}
-
-	for key, value := range encoder.Fields {
-		if v, ok := value.(string); ok {
-			tags[key] = v
-		} else {
-			tags[key] = fmt.Sprintf("%v", value)
-		}
-	}
-
-	// Add tags and extra into the packet.
-	if len(tags) != 0 {
-		event.Tags = tags
-	}
-
-	hub := sentry.CurrentHub()
 	// Capture the packet.
 	_ = c.client.CaptureEvent(event, nil, hub.Scope())
 	return nil
 }
 
+// fieldStrings returns the elements of an array field, such as fields.Fingerprint, as strings.
+func fieldStrings(field zapcore.Field) []string {
+	encoder := zapcore.NewMapObjectEncoder()
+	field.AddTo(encoder)
+
+	switch v := encoder.Fields[field.Key].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, value := range v {
+			values = append(values, fmt.Sprint(value))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
 func filterFrames(frames []sentry.Frame) []sentry.Frame {
 	if len(frames) == 0 {
 		return nil
@@ -242,3 +302,99 @@ func (c *Core) Sync() error {
 	c.client.Flush(c.sentryFlushTimeout)
 	return nil
 }
+
+// addBreadcrumb records the entry as a breadcrumb on the scope of the hub field, or of the current hub.
+func (c *Core) addBreadcrumb(entry zapcore.Entry, fields []zapcore.Field) {
+	encoder := zapcore.NewMapObjectEncoder()
+	hub := sentry.CurrentHub()
+	for _, field := range append(c.fields[:len(c.fields):len(c.fields)], fields...) {
+		if h, ok := field.Interface.(*sentry.Hub); ok && field.Key == hubKey && h != nil {
+			hub = h
+			continue
+		}
+		field.AddTo(encoder)
+	}
+
+	breadcrumb := &sentry.Breadcrumb{
+		Type:      "default",
+		Category:  entry.LoggerName,
+		Message:   entry.Message,
+		Level:     zapLevelToSentrySeverity[entry.Level],
+		Timestamp: entry.Time,
+	}
+	if len(encoder.Fields) != 0 {
+		breadcrumb.Data = encoder.Fields
+	}
+
+	// The hub is bound to the core client, to apply its MaxBreadcrumbs and BeforeBreadcrumb options.
+	sentry.NewHub(c.client, hub.Scope()).AddBreadcrumb(breadcrumb, nil)
+}
+
+// typeNamer is implemented by errors reporting the type of the error they wrap, such as the errors
+// with a message redacted by a redact.Core.
+type typeNamer interface {
+	TypeName() string
+}
+
+// setExceptionTypes sets the type of the exceptions whose error is a typeNamer.
+// Exceptions are matched with the errors of the chain by their Mechanism.ExceptionID,
+// the index of the error in the walk of sentry.Event.SetException.
+func setExceptionTypes(event *sentry.Event, err error, maxErrorDepth int) {
+	errs := walkErrors(err, nil, make(map[string]bool), maxErrorDepth, 0)
+
+	for i := range event.Exception {
+		id := 0
+		if mechanism := event.Exception[i].Mechanism; mechanism != nil {
+			id = mechanism.ExceptionID
+		}
+		if id >= len(errs) {
+			continue
+		}
+		if namer, ok := errs[id].(typeNamer); ok {
+			event.Exception[i].Type = namer.TypeName()
+		}
+	}
+}
+
+// walkErrors appends the errors of the chain of err to errs, depth first,
+// skipping the errors already seen, as sentry.Event.SetException does.
+func walkErrors(err error, errs []error, seen map[string]bool, maxErrorDepth, depth int) []error {
+	if err == nil {
+		return errs
+	}
+
+	// Pointers are compared by address, other errors by type and message.
+	key := reflect.TypeOf(err).String() + err.Error()
+	if v := reflect.ValueOf(err); v.Kind() == reflect.Ptr && !v.IsNil() {
+		key = fmt.Sprintf("%p", err)
+	}
+	if seen[key] {
+		return errs
+	}
+	seen[key] = true
+
+	errs = append(errs, err)
+	if maxErrorDepth >= 0 && depth >= maxErrorDepth {
+		return errs
+	}
+
+	switch v := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range v.Unwrap() {
+			errs = walkErrors(inner, errs, seen, maxErrorDepth, depth+1)
+		}
+	case interface{ Unwrap() error }:
+		errs = walkErrors(v.Unwrap(), errs, seen, maxErrorDepth, depth+1)
+	case interface{ Cause() error }:
+		errs = walkErrors(v.Cause(), errs, seen, maxErrorDepth, depth+1)
+	}
+	return errs
+}
+
+// maxErrorDepth returns the MaxErrorDepth client option, or DefaultMaxErrorDepth when unset.
+func (c *Core) maxErrorDepth() int {
+	if depth := c.client.Options().MaxErrorDepth; depth != 0 {
+		return depth
+	}
+	return DefaultMaxErrorDepth
+}
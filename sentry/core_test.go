@@ -1,15 +1,59 @@
 package zapsentry_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	ecsfields "go.pixelfactory.io/pkg/observability/log/fields"
 	zapsentry "go.pixelfactory.io/pkg/observability/log/sentry"
 )
 
+// transport records the Sentry events.
+type transport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *transport) Flush(time.Duration) bool { return true }
+
+func (t *transport) FlushWithContext(context.Context) bool { return true }
+
+func (t *transport) Configure(sentry.ClientOptions) {}
+
+func (t *transport) Close() {}
+
+func (t *transport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *transport) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.events
+}
+
+func setupClient(t *testing.T) (*sentry.Client, *transport) {
+	t.Helper()
+	tr := &transport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://public@sentry.test/1", Transport: tr})
+	require.NoError(t, err)
+	return client, tr
+}
+
 func TestSentryCore_NewCore(t *testing.T) {
 	t.Parallel()
 	is := require.New(t)
@@ -18,3 +62,190 @@ func TestSentryCore_NewCore(t *testing.T) {
 	is.NotEmpty(sentryCore)
 	is.Implements((*zapcore.Core)(nil), sentryCore)
 }
+
+//nolint:paralleltest // Breadcrumbs are recorded on the global hub scope.
+func TestSentryCore_SetBreadcrumbLevel(t *testing.T) {
+	is := require.New(t)
+	client, tr := setupClient(t)
+	t.Cleanup(sentry.CurrentHub().Scope().ClearBreadcrumbs)
+
+	core := zapsentry.NewCore(zapcore.ErrorLevel, client, zapsentry.SetBreadcrumbLevel(zapcore.InfoLevel))
+	is.False(core.Enabled(zapcore.DebugLevel))
+	is.True(core.Enabled(zapcore.InfoLevel))
+
+	logger := zap.New(core).Named("db")
+	logger.Debug("ignored")
+	logger.With(zap.String("table", "users")).Info("query", zap.Int("rows", 2))
+	logger.Error("failed")
+
+	events := tr.Events()
+	is.Len(events, 1)
+	is.Equal("failed", events[0].Message)
+	is.Len(events[0].Breadcrumbs, 1)
+
+	breadcrumb := events[0].Breadcrumbs[0]
+	is.Equal("query", breadcrumb.Message)
+	is.Equal("db", breadcrumb.Category)
+	is.Equal(sentry.LevelInfo, breadcrumb.Level)
+	is.Equal(map[string]interface{}{"table": "users", "rows": int64(2)}, breadcrumb.Data)
+}
+
+func TestSentryCore_Hub(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	client, tr := setupClient(t)
+
+	hub := sentry.NewHub(client, sentry.NewScope())
+	hub.Scope().SetTag("tenant", "acme")
+
+	core := zapsentry.NewCore(zapcore.ErrorLevel, client, zapsentry.SetBreadcrumbLevel(zapcore.InfoLevel))
+	logger := zap.New(core).With(zapsentry.Hub(hub))
+	logger.Info("request")
+	logger.Error("failed")
+	zap.New(core).Error("global")
+
+	events := tr.Events()
+	is.Len(events, 2)
+	is.Equal("acme", events[0].Tags["tenant"])
+	is.NotContains(events[0].Tags, "sentry.hub")
+	is.Len(events[0].Breadcrumbs, 1)
+	is.NotContains(events[1].Tags, "tenant")
+}
+
+func TestSentryCore_ErrorChain(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	client, tr := setupClient(t)
+	logger := zap.New(zapsentry.NewCore(zapcore.ErrorLevel, client))
+
+	errTimeout := errors.New("timeout")
+	errClosed := errors.New("closed")
+	err := fmt.Errorf("query users: %w", errors.Join(errTimeout, errClosed))
+	logger.Error("failed", zap.Error(err))
+
+	events := tr.Events()
+	is.Len(events, 1)
+
+	exceptions := events[0].Exception
+	is.Len(exceptions, 4)
+	// The outermost error is last.
+	outer := exceptions[3]
+	is.Equal(err.Error(), outer.Value)
+	is.Equal("*fmt.wrapError", outer.Type)
+	is.NotNil(outer.Stacktrace)
+	is.Equal(0, outer.Mechanism.ExceptionID)
+
+	join := exceptions[2]
+	is.Equal("*errors.joinError", join.Type)
+	is.True(join.Mechanism.IsExceptionGroup)
+	is.Equal(0, *join.Mechanism.ParentID)
+
+	is.Equal("timeout", exceptions[1].Value)
+	is.Equal("errors[0]", exceptions[1].Mechanism.Source)
+	is.Equal(join.Mechanism.ExceptionID, *exceptions[1].Mechanism.ParentID)
+	is.Equal("closed", exceptions[0].Value)
+}
+
+func TestSentryCore_WithFingerprinter(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	client, tr := setupClient(t)
+
+	core := zapsentry.NewCore(zapcore.ErrorLevel, client, zapsentry.WithFingerprinter(
+		func(entry zapcore.Entry, fields []zapcore.Field) []string {
+			return []string{entry.LoggerName, fields[0].String}
+		},
+	))
+	logger := zap.New(core).Named("db").With(zap.String("table", "users"))
+	logger.Error("user 42 not found")
+	logger.Error("user 43 not found",
+		ecsfields.Fingerprint("{{ default }}", "not-found"),
+		ecsfields.SentryTransaction("GET /users/{id}"),
+	)
+
+	events := tr.Events()
+	is.Len(events, 2)
+	is.Equal([]string{"db", "users"}, events[0].Fingerprint)
+	is.Empty(events[0].Transaction)
+	is.Equal([]string{"{{ default }}", "not-found"}, events[1].Fingerprint)
+	is.Equal("GET /users/{id}", events[1].Transaction)
+	is.NotContains(events[1].Tags, ecsfields.FingerprintKey)
+	is.NotContains(events[1].Tags, ecsfields.TransactionKey)
+}
+
+func TestSentryCore_Interfaces(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	client, tr := setupClient(t)
+	logger := zap.New(zapsentry.NewCore(zapcore.ErrorLevel, client))
+
+	req := httptest.NewRequest(http.MethodPost, "http://test/users?page=2", http.NoBody)
+	req.Header.Set("Accept", "text/plain")
+	logger.Error("failed",
+		ecsfields.HTTPRequest(req),
+		ecsfields.URL(req.URL),
+		zap.Object("service", &ecsfields.ServiceField{Name: "api", Version: "1.2.0", NodeName: "pod-1"}),
+		ecsfields.Source("10.0.0.1", 1234),
+		zap.String("http.request.id", "abc"),
+		zap.String("user.id", "42"),
+		zap.String("user.email", "john@example.com"),
+		zap.String("body", strings.Repeat("a", 201)),
+	)
+
+	events := tr.Events()
+	is.Len(events, 1)
+	event := events[0]
+
+	is.Equal(&sentry.Request{
+		URL:         "http://test/users?page=2",
+		Method:      http.MethodPost,
+		QueryString: "page=2",
+		Headers:     map[string]string{"Accept": "text/plain"},
+	}, event.Request)
+	is.Equal("42", event.User.ID)
+	is.Equal("john@example.com", event.User.Email)
+	is.Equal("api@1.2.0", event.Release)
+	is.Equal("pod-1", event.ServerName)
+	is.Equal(sentry.Context{"ip": "10.0.0.1", "port": 1234}, event.Contexts["source"])
+	is.Equal(sentry.Context{"version": "HTTP/1.1", "referrer": "", "bytes": int64(0)}, event.Contexts["http.request"])
+	is.Equal(sentry.Context{"scheme": "http", "domain": "test"}, event.Contexts["url"])
+	is.NotContains(event.Contexts, "service")
+	is.NotContains(event.Contexts, "user")
+	is.Equal(map[string]string{
+		"service.name":        "api",
+		"service.version":     "1.2.0",
+		"service.node.name":   "pod-1",
+		"http.request.method": http.MethodPost,
+		"log.level":           "error",
+	}, event.Tags)
+	is.Equal(map[string]interface{}{
+		"http.request.id": "abc",
+		"body":            strings.Repeat("a", 201),
+	}, event.Extra)
+}
+
+func TestSentryCore_WithTagKeys(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	client, tr := setupClient(t)
+	logger := zap.New(zapsentry.NewCore(zapcore.ErrorLevel, client,
+		zapsentry.WithTagKeys("tenant"),
+		zapsentry.WithTagKeys("db.name"),
+		zapsentry.WithExtraKeys("query"),
+	))
+
+	logger.Error("failed",
+		zap.String("tenant", "acme"),
+		zap.Object("db", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("name", "users")
+			return nil
+		})),
+		zap.String("query", "SELECT 1"),
+		zap.Int("rows", 0),
+	)
+
+	events := tr.Events()
+	is.Len(events, 1)
+	is.Equal(map[string]string{"tenant": "acme", "db.name": "users", "log.level": "error"}, events[0].Tags)
+	is.Equal(map[string]interface{}{"query": "SELECT 1"}, events[0].Extra)
+}
@@ -0,0 +1,279 @@
+package zapsentry
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxTagValueLength is the maximum length of Sentry tag values, longer values are reported as extras.
+const maxTagValueLength = 200
+
+// Keys of the ECS objects mapped to Sentry interfaces.
+const (
+	httpRequestKey = "http.request"
+	urlKey         = "url"
+	userKey        = "user"
+)
+
+// WithTagKeys adds keys of fields reported as event tags, e.g. "http.request.id".
+// Nested fields are matched by their dotted path. By default, only the service.* fields,
+// http.request.method and log.level are tags, other scalar fields are extras.
+func WithTagKeys(keys ...string) Option {
+	return func(core *Core) {
+		if core.tagKeys == nil {
+			core.tagKeys = make(map[string]bool, len(keys))
+		}
+		for _, key := range keys {
+			core.tagKeys[key] = true
+		}
+	}
+}
+
+// WithExtraKeys sets the keys of the fields reported as event extras, when they aren't tags.
+// Other fields are dropped, objects excepted. By default, fields that aren't tags are extras.
+func WithExtraKeys(keys ...string) Option {
+	return func(core *Core) {
+		core.extraKeys = keySet(keys)
+	}
+}
+
+// setInterfaces maps the encoded fields to the event interfaces:
+//   - http.request and url to Request, their other fields to Contexts,
+//   - user to User,
+//   - service to Release and ServerName, from service.node.name,
+//   - other objects to Contexts,
+//   - scalars to Extra, see WithExtraKeys.
+//
+// The service.* fields, http.request.method, log.level and the WithTagKeys fields are also tags.
+func (c *Core) setInterfaces(event *sentry.Event, level zapcore.Level, fields map[string]interface{}) {
+	flat := make(map[string]interface{}, len(fields))
+	flatten(flat, "", fields)
+
+	event.Request = request(flat)
+	if user := user(flat); !user.IsEmpty() {
+		event.User = user
+	}
+
+	if name, ok := flat[serviceKey+".name"].(string); ok {
+		version, _ := flat[serviceKey+".version"].(string)
+		event.Release = release(name, version)
+	}
+	if name, ok := flat[serviceKey+".node.name"].(string); ok {
+		event.ServerName = name
+	}
+
+	tags := map[string]string{"log.level": level.String()}
+	for key, value := range flat {
+		if !c.isTagKey(key) {
+			continue
+		}
+		if tag, ok := tagValue(value); ok {
+			tags[key] = tag
+		}
+	}
+
+	extra := make(map[string]interface{})
+	for key, value := range fields {
+		if obj, ok := value.(map[string]interface{}); ok {
+			if rest := unmapped(key+".", obj); len(rest) != 0 {
+				if event.Contexts == nil {
+					event.Contexts = make(map[string]sentry.Context)
+				}
+				event.Contexts[key] = rest
+			}
+			continue
+		}
+
+		if _, isTag := tags[key]; isTag || mapped(key) {
+			continue
+		}
+		if c.extraKeys == nil || c.extraKeys[key] {
+			extra[key] = value
+		}
+	}
+
+	// Add tags and extra into the packet.
+	event.Tags = tags
+	if len(extra) != 0 {
+		event.Extra = extra
+	}
+}
+
+// isTagKey reports whether the field with the dotted key is reported as a tag.
+func (c *Core) isTagKey(key string) bool {
+	return strings.HasPrefix(key, serviceKey+".") || key == httpRequestKey+".method" || c.tagKeys[key]
+}
+
+// tagValue returns the tag value of a scalar field, and whether it fits in a tag.
+func tagValue(value interface{}) (string, bool) {
+	var tag string
+	switch v := value.(type) {
+	case string:
+		tag = v
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, time.Time, time.Duration:
+		tag = fmt.Sprintf("%v", v)
+	default:
+		return "", false
+	}
+	return tag, len(tag) <= maxTagValueLength
+}
+
+// request returns the Sentry request of the http.request and url fields, or nil.
+func request(flat map[string]interface{}) *sentry.Request {
+	req := &sentry.Request{}
+	req.Method, _ = flat[httpRequestKey+".method"].(string)
+	req.Data, _ = flat[httpRequestKey+".body.content"].(string)
+	req.QueryString, _ = flat[urlKey+".query"].(string)
+	if req.URL, _ = flat[urlKey+".full"].(string); req.URL == "" {
+		req.URL, _ = flat[urlKey+".path"].(string)
+	}
+
+	if headers := headers(flat); len(headers) != 0 {
+		req.Headers = headers
+	}
+
+	if req.Method == "" && req.URL == "" && req.Headers == nil {
+		return nil
+	}
+	return req
+}
+
+// headers returns the headers logged by fields.HTTPRequest with their values joined.
+// Redacted headers are objects, flattened.
+func headers(flat map[string]interface{}) map[string]string {
+	const key = httpRequestKey + ".headers"
+
+	values := make(map[string]string)
+	switch v := flat[key].(type) {
+	case http.Header:
+		for name := range v {
+			values[name] = strings.Join(v[name], ", ")
+		}
+	case map[string][]string:
+		for name := range v {
+			values[name] = strings.Join(v[name], ", ")
+		}
+	}
+
+	for path, value := range flat {
+		name, ok := strings.CutPrefix(path, key+".")
+		if !ok {
+			continue
+		}
+
+		if elements, isArray := value.([]interface{}); isArray {
+			parts := make([]string, 0, len(elements))
+			for _, element := range elements {
+				parts = append(parts, fmt.Sprint(element))
+			}
+			values[name] = strings.Join(parts, ", ")
+		} else {
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
+
+// user returns the Sentry user of the ECS user fields.
+func user(flat map[string]interface{}) sentry.User {
+	u := sentry.User{}
+	for key, value := range flat {
+		name, ok := strings.CutPrefix(key, userKey+".")
+		if !ok {
+			continue
+		}
+
+		s := fmt.Sprint(value)
+		switch name {
+		case "id":
+			u.ID = s
+		case "email":
+			u.Email = s
+		case "name":
+			u.Username = s
+		case "full_name":
+			u.Name = s
+		default:
+			if u.Data == nil {
+				u.Data = make(map[string]string)
+			}
+			u.Data[name] = s
+		}
+	}
+	return u
+}
+
+// release returns the Sentry release of a service, "name@version".
+func release(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "@" + version
+}
+
+// mapped reports whether the field with the dotted key is mapped to the Request, User or Release interfaces.
+// Other http.request, url and service fields, such as http.request.referrer or url.domain, are kept.
+func mapped(key string) bool {
+	switch key {
+	case userKey,
+		httpRequestKey + ".method", httpRequestKey + ".headers", httpRequestKey + ".body.content",
+		urlKey + ".full", urlKey + ".path", urlKey + ".query",
+		serviceKey + ".name", serviceKey + ".version", serviceKey + ".node.name":
+		return true
+	}
+	return strings.HasPrefix(key, userKey+".") || strings.HasPrefix(key, httpRequestKey+".headers.")
+}
+
+// unmapped returns the fields of obj, whose keys have prefix, that aren't mapped.
+// Objects left empty are omitted.
+func unmapped(prefix string, obj map[string]interface{}) map[string]interface{} {
+	rest := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		path := prefix + key
+		if mapped(path) {
+			continue
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			if nested = unmapped(path+".", nested); len(nested) != 0 {
+				rest[key] = nested
+			}
+			continue
+		}
+		rest[key] = value
+	}
+	return rest
+}
+
+// flatten adds the values of fields to flat with their dotted path.
+func flatten(flat map[string]interface{}, prefix string, fields map[string]interface{}) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	// Sorted for a consistent precedence between nested values and dotted keys.
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if obj, ok := fields[key].(map[string]interface{}); ok {
+			flatten(flat, prefix+key+".", obj)
+			continue
+		}
+		flat[prefix+key] = fields[key]
+	}
+}
+
+func keySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
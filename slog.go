@@ -0,0 +1,219 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler is a slog.Handler writing through a DefaultLogger zap core.
+type slogHandler struct {
+	logger *DefaultLogger
+	core   zapcore.Core
+	// groups are the groups opened by WithGroup, with the attributes added to them since.
+	// They are only written with the record attributes, so the core fields stay at the top level.
+	groups []slogGroup
+}
+
+// slogGroup is a group opened by WithGroup.
+type slogGroup struct {
+	name   string
+	fields []zapcore.Field
+}
+
+// SlogHandler returns a slog.Handler writing through the same core, level and
+// Sentry integration as the logger.
+// slog.Group attributes and WithGroup groups are written as nested objects, empty
+// ones are omitted. Attributes holding a zapcore.ObjectMarshaler
+// (e.g. fields.ServiceField) or a zapcore.Field are marshalled like their zap counterparts.
+func (l *DefaultLogger) SlogHandler() slog.Handler {
+	return &slogHandler{
+		logger: l,
+		core:   l.logger.Core(),
+	}
+}
+
+// NewSlogHandler returns a slog.Handler backed by a new logger built with opts.
+func NewSlogHandler(opts ...Option) slog.Handler {
+	return New(opts...).SlogHandler()
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.core.Enabled(slogToZapLevel(level))
+}
+
+// Handle converts the slog.Record into a zap entry and writes it.
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	entry := zapcore.Entry{
+		Level:      slogToZapLevel(record.Level),
+		Time:       record.Time,
+		Message:    record.Message,
+		LoggerName: h.logger.logger.Name(),
+	}
+
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       frame.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+
+	checked := h.core.Check(entry, nil)
+	if checked == nil {
+		return nil
+	}
+
+	fields := make([]zapcore.Field, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		if field, ok := slogAttrToField(attr); ok {
+			fields = append(fields, field)
+		}
+		return true
+	})
+
+	checked.Write(h.logger.contextFields(ctx, h.nest(fields))...)
+	return nil
+}
+
+// WithAttrs returns a handler whose entries include attrs, in the current group if any.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := slogAttrsToFields(attrs)
+	if len(fields) == 0 {
+		return h
+	}
+
+	clone := *h
+	if len(h.groups) == 0 {
+		clone.core = h.core.With(fields)
+		return &clone
+	}
+
+	clone.groups = append([]slogGroup(nil), h.groups...)
+	last := &clone.groups[len(clone.groups)-1]
+	last.fields = append(last.fields[:len(last.fields):len(last.fields)], fields...)
+	return &clone
+}
+
+// WithGroup returns a handler nesting subsequent attributes under name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.groups = append(h.groups[:len(h.groups):len(h.groups)], slogGroup{name: name})
+	return &clone
+}
+
+// nest returns the record fields nested in the handler groups, along with the group attributes.
+// Groups without attributes are omitted.
+func (h *slogHandler) nest(fields []zapcore.Field) []zapcore.Field {
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		group := h.groups[i]
+		if len(group.fields) == 0 && len(fields) == 0 {
+			continue
+		}
+
+		nested := make(slogFields, 0, len(group.fields)+len(fields))
+		nested = append(append(nested, group.fields...), fields...)
+		fields = []zapcore.Field{zap.Object(group.name, nested)}
+	}
+	return fields
+}
+
+// slogFields marshals the fields of a group as a nested object.
+type slogFields []zapcore.Field
+
+// MarshalLogObject implements zapcore ObjectMarshaler.
+func (f slogFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for i := range f {
+		f[i].AddTo(enc)
+	}
+	return nil
+}
+
+// slogAttrsToFields converts slog attributes to zap fields, skipping the ignored ones.
+func slogAttrsToFields(attrs []slog.Attr) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		if field, ok := slogAttrToField(attr); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// slogAttrToField converts a slog.Attr to a zap.Field.
+// False is returned when the attribute must be ignored.
+func slogAttrToField(attr slog.Attr) (zapcore.Field, bool) {
+	value := attr.Value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindBool:
+		return zap.Bool(attr.Key, value.Bool()), true
+	case slog.KindDuration:
+		return zap.Duration(attr.Key, value.Duration()), true
+	case slog.KindFloat64:
+		return zap.Float64(attr.Key, value.Float64()), true
+	case slog.KindInt64:
+		return zap.Int64(attr.Key, value.Int64()), true
+	case slog.KindString:
+		return zap.String(attr.Key, value.String()), true
+	case slog.KindTime:
+		return zap.Time(attr.Key, value.Time()), true
+	case slog.KindUint64:
+		return zap.Uint64(attr.Key, value.Uint64()), true
+	case slog.KindGroup:
+		group := slogAttrsToFields(value.Group())
+		if len(group) == 0 {
+			return zapcore.Field{}, false
+		}
+		// Groups with an empty key are inlined.
+		if attr.Key == "" {
+			return zap.Inline(slogFields(group)), true
+		}
+		return zap.Object(attr.Key, slogFields(group)), true
+	case slog.KindLogValuer, slog.KindAny:
+		return slogAnyToField(attr.Key, value.Any())
+	}
+
+	return zap.Any(attr.Key, value.Any()), true
+}
+
+// slogAnyToField converts a slog.KindAny value to a zap.Field.
+func slogAnyToField(key string, value any) (zapcore.Field, bool) {
+	switch v := value.(type) {
+	case zapcore.Field:
+		return v, true
+	case error:
+		return zap.NamedError(key, v), true
+	case nil:
+		if key == "" {
+			return zapcore.Field{}, false
+		}
+	}
+
+	return zap.Any(key, value), true
+}
+
+// slogToZapLevel maps a slog.Level to the closest zapcore.Level.
+func slogToZapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
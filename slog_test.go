@@ -0,0 +1,191 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.pixelfactory.io/pkg/observability/log"
+	"go.pixelfactory.io/pkg/observability/log/fields"
+)
+
+func Test_SlogHandler(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	slogger := slog.New(logger.SlogHandler())
+	slogger.Info(message, slog.String(testKey, testValue))
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(zap.InfoLevel, entry.Level)
+		is.Equal(message, entry.Message)
+		is.True(entry.Caller.Defined)
+		is.Equal([]zapcore.Field{
+			zap.String(testKey, testValue),
+		}, entry.Context)
+	}
+}
+
+func Test_SlogHandler_Levels(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	slogger := slog.New(logger.SlogHandler())
+	slogger.Debug(message)
+	slogger.Info(message)
+	slogger.Warn(message)
+	slogger.Error(message)
+	slogger.Log(context.Background(), slog.LevelError+4, message)
+
+	levels := make([]zapcore.Level, 0, logs.Len())
+	for _, entry := range logs.All() {
+		levels = append(levels, entry.Level)
+	}
+	is.Equal([]zapcore.Level{
+		zap.DebugLevel,
+		zap.InfoLevel,
+		zap.WarnLevel,
+		zap.ErrorLevel,
+		zap.ErrorLevel,
+	}, levels)
+}
+
+func Test_SlogHandler_Enabled(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	level := zap.NewAtomicLevelAt(zapcore.WarnLevel)
+	obsOpts, logs := setupObserver(level)
+	logger := log.New(log.WithZapOption(obsOpts))
+
+	slogger := slog.New(logger.SlogHandler())
+	slogger.Info(message)
+	slogger.Warn(message)
+
+	is.Equal(1, logs.Len())
+}
+
+func Test_SlogHandler_Fields(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+	err := errors.New("test error")
+
+	slogger := slog.New(logger.SlogHandler()).With(slog.String("with", "attr"))
+	slogger.Info(message,
+		slog.Any("service", &fields.ServiceField{Name: "testSvc", Version: "0.0.1"}),
+		slog.Any("", fields.Source("10.0.0.1", 8080)),
+		slog.Any("error", err),
+		slog.Group("group", slog.Int("count", 1)),
+	)
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(map[string]interface{}{
+			"with": "attr",
+			"service": map[string]interface{}{
+				"name":    "testSvc",
+				"version": "0.0.1",
+			},
+			"source": map[string]interface{}{
+				"ip":   "10.0.0.1",
+				"port": 8080,
+			},
+			"error": err.Error(),
+			"group": map[string]interface{}{
+				"count": int64(1),
+			},
+		}, entry.ContextMap())
+	}
+}
+
+func Test_SlogHandler_WithGroup(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	slogger := slog.New(logger.SlogHandler()).WithGroup("http").With(slog.String("method", "GET"))
+	slogger.Info(message, slog.Int("status", 200))
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		entry := logs.All()[0]
+		is.Equal(map[string]interface{}{
+			"http": map[string]interface{}{
+				"method": "GET",
+				"status": int64(200),
+			},
+		}, entry.ContextMap())
+	}
+}
+
+func Test_SlogHandler_WithGroup_JSON(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	var buf bytes.Buffer
+
+	logger := log.New(log.WithOutput(&buf))
+	slogger := slog.New(logger.SlogHandler()).
+		With(slog.String("top", "level")).
+		WithGroup("http").With(slog.String("method", "GET")).
+		WithGroup("response").WithGroup("empty")
+	slogger.Info(message, slog.Group("ignored"))
+
+	var entry map[string]interface{}
+	is.NoError(json.Unmarshal(buf.Bytes(), &entry))
+	is.Contains(entry, "ecs.version")
+	is.Equal("level", entry["top"])
+	is.Equal(map[string]interface{}{"method": "GET"}, entry["http"])
+
+	buf.Reset()
+	slogger.Info(message, slog.Int("status", 200))
+
+	entry = nil
+	is.NoError(json.Unmarshal(buf.Bytes(), &entry))
+	is.Contains(entry, "ecs.version")
+	is.Equal(map[string]interface{}{
+		"method": "GET",
+		"response": map[string]interface{}{
+			"empty": map[string]interface{}{"status": float64(200)},
+		},
+	}, entry["http"])
+}
+
+func Test_SlogHandler_ContextFields(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+	logger, logs := setupLogger()
+
+	ctx := log.ContextWithFields(context.Background(), zap.String("request.id", "1"))
+	slog.New(logger.SlogHandler()).InfoContext(ctx, message)
+
+	if logs.Len() != 1 {
+		t.Errorf("No logs")
+	} else {
+		is.Equal([]zapcore.Field{zap.String("request.id", "1")}, logs.All()[0].Context)
+	}
+}
+
+func Test_NewSlogHandler(t *testing.T) {
+	t.Parallel()
+	is := require.New(t)
+
+	handler := log.NewSlogHandler(log.WithLevel("warn"))
+
+	is.False(handler.Enabled(context.Background(), slog.LevelInfo))
+	is.True(handler.Enabled(context.Background(), slog.LevelWarn))
+}